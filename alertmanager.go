@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// alertmanagerPayload Alertmanager webhook (version 4) 请求结构
+type alertmanagerPayload struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []alertmanagerAlert `json:"alerts"`
+}
+
+// alertmanagerAlert 单条告警
+type alertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+func init() {
+	RegisterWebhookAdapter("alertmanager", parseAlertmanagerWebhook)
+}
+
+// parseAlertmanagerWebhook 将 Alertmanager 告警分组按 firing/resolved 状态各转换为一条消息，过长时拆分
+func parseAlertmanagerWebhook(req *webhookRequest) ([]webhookMessage, error) {
+	var payload alertmanagerPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Version != "" && payload.Version != "4" {
+		return nil, fmt.Errorf("不支持的 Alertmanager webhook 版本: %s", payload.Version)
+	}
+
+	var messages []webhookMessage
+	for _, status := range []string{"firing", "resolved"} {
+		var alerts []alertmanagerAlert
+		for _, alert := range payload.Alerts {
+			if alert.Status == status {
+				alerts = append(alerts, alert)
+			}
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+
+		title := alertmanagerTitle(payload, status, len(alerts))
+		header := title + "\n"
+		if labels := formatLabels(payload.CommonLabels, nil); labels != "" {
+			header += "公共标签: " + labels + "\n"
+		}
+		if payload.ExternalURL != "" {
+			header += "Alertmanager: " + payload.ExternalURL + "\n"
+		}
+		header += "\n"
+
+		blocks := make([]string, len(alerts))
+		for i, alert := range alerts {
+			blocks[i] = formatAlertmanagerAlert(i+1, alert, payload.CommonLabels)
+		}
+		if payload.TruncatedAlerts > 0 && status == payload.Status {
+			blocks = append(blocks, fmt.Sprintf("另有 %d 条告警被 Alertmanager 截断", payload.TruncatedAlerts))
+		}
+
+		messages = append(messages, splitAlertMessages(title, header, blocks, req.MaxLength, map[string]string{
+			"status":       status,
+			"alertname":    payload.CommonLabels["alertname"],
+			"severity":     payload.CommonLabels["severity"],
+			"receiver":     payload.Receiver,
+			"external_url": payload.ExternalURL,
+			"alert_count":  strconv.Itoa(len(alerts)),
+		})...)
+	}
+	return messages, nil
+}
+
+// splitAlertMessages 按长度上限拆分告警消息，拆分后的标题和正文带有 (序号/总数)，每条消息都包含 params 中的参数
+func splitAlertMessages(title, header string, blocks []string, maxLength int, params map[string]string) []webhookMessage {
+	parts := splitMessage(header, blocks, maxLength, 0)
+	messages := make([]webhookMessage, len(parts))
+	for i, part := range parts {
+		partTitle := title
+		if len(parts) > 1 {
+			partTitle = fmt.Sprintf("%s (%d/%d)", title, i+1, len(parts))
+			part = fmt.Sprintf("(%d/%d) %s", i+1, len(parts), part)
+		}
+
+		messageParams := copyParams(params)
+		messageParams["title"] = partTitle
+		messageParams["msg"] = strings.TrimRight(part, "\n")
+		messages[i] = webhookMessage{Params: messageParams}
+	}
+	return messages
+}
+
+// alertmanagerTitle 生成告警标题，例如 🔥 [FIRING:2] HighCPU
+func alertmanagerTitle(payload alertmanagerPayload, status string, count int) string {
+	name := payload.GroupLabels["alertname"]
+	if name == "" {
+		name = payload.CommonLabels["alertname"]
+	}
+	if name == "" {
+		name = formatLabels(payload.GroupLabels, nil)
+	}
+	return alertTitle(status, count, name)
+}
+
+// alertTitle 生成带状态图标的告警标题
+func alertTitle(status string, count int, name string) string {
+	emoji := "🔥"
+	if status == "resolved" {
+		emoji = "✅"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s [%s:%d] %s", emoji, strings.ToUpper(status), count, name))
+}
+
+// formatAlertmanagerAlert 格式化单条告警，标签中省略公共标签
+func formatAlertmanagerAlert(index int, alert alertmanagerAlert, commonLabels map[string]string) string {
+	lines := []string{strings.TrimSpace(fmt.Sprintf("[%d] %s", index, formatLabels(alert.Labels, commonLabels)))}
+
+	// summary 和 description 优先显示，其余注解按名称排序
+	keys := make([]string, 0, len(alert.Annotations))
+	for key := range alert.Annotations {
+		if key != "summary" && key != "description" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	keys = append([]string{"summary", "description"}, keys...)
+	for _, key := range keys {
+		if value := alert.Annotations[key]; value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", key, value))
+		}
+	}
+
+	if !alert.StartsAt.IsZero() {
+		lines = append(lines, "开始时间: "+alert.StartsAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if alert.Status == "resolved" && !alert.EndsAt.IsZero() {
+		lines = append(lines, "恢复时间: "+alert.EndsAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if alert.GeneratorURL != "" {
+		lines = append(lines, "来源: "+alert.GeneratorURL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatLabels 按名称排序格式化标签，跳过 exclude 中取值相同的标签
+func formatLabels(labels map[string]string, exclude map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key, value := range labels {
+		if excluded, ok := exclude[key]; ok && excluded == value {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + labels[key]
+	}
+	return strings.Join(pairs, ", ")
+}
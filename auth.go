@@ -0,0 +1,64 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AuthConfig 推送路由鉴权配置
+type AuthConfig struct {
+	Keys []string `json:"keys"` // 允许的访问令牌，配置多个便于轮换
+}
+
+// requestToken 从请求中提取访问令牌，依次检查 Bearer Token、X-Api-Key 请求头和 token 查询参数
+func requestToken(r *http.Request) string {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+			return strings.TrimSpace(authorization[7:])
+		}
+	}
+	if apiKey := r.Header.Get("X-Api-Key"); apiKey != "" {
+		return apiKey
+	}
+	return r.URL.Query().Get("token")
+}
+
+// authKeys 返回访问配置所需的令牌列表及是否需要鉴权
+// 配置自身的 auth 优先于全局 auth；配置不存在时，只要启用了任意鉴权就要求鉴权，避免暴露配置是否存在
+func (cm *ConfigManager) authKeys(config PushConfig, exists bool) ([]string, bool) {
+	if exists && config.Auth != nil {
+		return config.Auth.Keys, len(config.Auth.Keys) > 0
+	}
+	if cm.Auth != nil && len(cm.Auth.Keys) > 0 {
+		return cm.Auth.Keys, true
+	}
+	if !exists {
+		for _, c := range cm.Configs {
+			if c.Auth != nil && len(c.Auth.Keys) > 0 {
+				return nil, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// authorize 校验请求的访问令牌，返回 0 表示通过，否则返回应响应的HTTP状态码
+func (cm *ConfigManager) authorize(r *http.Request, config PushConfig, exists bool) int {
+	keys, required := cm.authKeys(config, exists)
+	if !required {
+		return 0
+	}
+
+	token := requestToken(r)
+	if token == "" {
+		return http.StatusUnauthorized
+	}
+
+	for _, key := range keys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return 0
+		}
+	}
+	return http.StatusForbidden
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// SendFunc 统一推送函数签名，ctx 取消或超时时应中止正在进行的请求
+type SendFunc func(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error)
+
+// ConfigField 推送渠道配置字段说明
+type ConfigField struct {
+	Name        string
+	Required    bool
+	Description string
+}
+
+// Channel 推送渠道接口
+type Channel interface {
+	// Type 返回配置文件中使用的推送类型名称，例如 dingtalk_text
+	Type() string
+	// Schema 返回该渠道支持的配置字段
+	Schema() []ConfigField
+	// Validate 校验配置是否完整有效
+	Validate(configData map[string]interface{}) error
+	// Send 发送消息
+	Send(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error)
+}
+
+// ReferenceChannel 引用其他推送配置的渠道（例如群发），配置校验时会检查引用的配置是否存在及是否循环引用
+type ReferenceChannel interface {
+	References(configData map[string]interface{}) []string
+}
+
+// LengthLimitChannel 有单条消息长度上限的渠道，webhook 适配器会按上限拆分过长的消息
+type LengthLimitChannel interface {
+	MaxMessageLength() int
+}
+
+// lengthLimitedChannel 为渠道附加消息长度上限
+type lengthLimitedChannel struct {
+	Channel
+	maxLength int
+}
+
+// WithMaxMessageLength 为渠道声明单条消息的长度上限（字节）
+func WithMaxMessageLength(channel Channel, maxLength int) Channel {
+	return lengthLimitedChannel{Channel: channel, maxLength: maxLength}
+}
+
+func (c lengthLimitedChannel) MaxMessageLength() int {
+	return c.maxLength
+}
+
+// funcChannel 基于函数实现的推送渠道
+type funcChannel struct {
+	typeName string
+	schema   []ConfigField
+	validate func(configData map[string]interface{}) error
+	send     SendFunc
+}
+
+// NewChannel 使用现有的转换函数和发送函数创建推送渠道
+func NewChannel(typeName string, schema []ConfigField, validate func(configData map[string]interface{}) error, send SendFunc) Channel {
+	return &funcChannel{
+		typeName: typeName,
+		schema:   schema,
+		validate: validate,
+		send:     send,
+	}
+}
+
+func (c *funcChannel) Type() string {
+	return c.typeName
+}
+
+func (c *funcChannel) Schema() []ConfigField {
+	return c.schema
+}
+
+func (c *funcChannel) Validate(configData map[string]interface{}) error {
+	if c.validate == nil {
+		return nil
+	}
+	return c.validate(configData)
+}
+
+func (c *funcChannel) Send(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	return c.send(ctx, configName, configData, params)
+}
+
+// 推送渠道注册表
+var channelRegistry = make(map[string]Channel)
+
+// RegisterChannel 注册推送渠道，通常在各渠道文件的 init 函数中调用
+func RegisterChannel(channel Channel) {
+	typeName := channel.Type()
+	if typeName == "" {
+		panic("推送渠道类型不能为空")
+	}
+	if _, exists := channelRegistry[typeName]; exists {
+		panic(fmt.Sprintf("推送渠道类型重复注册: %s", typeName))
+	}
+	channelRegistry[typeName] = channel
+}
+
+// GetChannel 获取指定类型的推送渠道
+func GetChannel(typeName string) (Channel, bool) {
+	channel, exists := channelRegistry[typeName]
+	return channel, exists
+}
+
+// GetAllChannelTypes 获取所有已注册的推送类型（按名称排序）
+func GetAllChannelTypes() []string {
+	types := make([]string, 0, len(channelRegistry))
+	for typeName := range channelRegistry {
+		types = append(types, typeName)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// maxMessageLength 返回配置可发送的单条消息长度上限（字节），0 表示不限制
+// 群发和故障转移等引用其他配置的渠道取所有目标中的最小值
+func maxMessageLength(configName string) int {
+	config, exists := getConfigManager().GetConfig(configName)
+	if !exists {
+		return 0
+	}
+	channel, supported := GetChannel(config.Type)
+	if !supported {
+		return 0
+	}
+
+	if limitChannel, ok := channel.(LengthLimitChannel); ok {
+		return limitChannel.MaxMessageLength()
+	}
+
+	maxLength := 0
+	if refChannel, ok := channel.(ReferenceChannel); ok {
+		for _, target := range refChannel.References(config.Config) {
+			if targetLength := maxMessageLength(target); targetLength > 0 && (maxLength == 0 || targetLength < maxLength) {
+				maxLength = targetLength
+			}
+		}
+	}
+	return maxLength
+}
+
+// sendToConfig 按配置名称查找推送渠道并发送消息
+func sendToConfig(ctx context.Context, configName string, params map[string]string) (string, error) {
+	config, exists := getConfigManager().GetConfig(configName)
+	if !exists {
+		return "", fmt.Errorf("配置 '%s' 不存在", configName)
+	}
+
+	channel, supported := GetChannel(config.Type)
+	if !supported {
+		return "", fmt.Errorf("不支持的推送类型: %s", config.Type)
+	}
+
+	// 检查限流，queue 模式下超限的消息暂存在内存中延后发送
+	wait, held, err := applyRateLimit(configName, config, ctx.Value(rateLimitQueueKey{}) == nil)
+	if err != nil {
+		return "", err
+	}
+	if wait > 0 {
+		origin := delayedOrigin{Job: retryJobFrom(ctx, configName), Group: groupTargetFrom(ctx, configName)}
+		go sendDelayed(configName, config, channel, params, wait, held, origin)
+		return fmt.Sprintf("Delayed: 触发限流，约 %d 秒后发送", int(math.Ceil(wait.Seconds()))), nil
+	}
+
+	return channel.Send(ctx, configName, config.Config, params)
+}
+
+// sendToTarget 发送到群发或故障转移中的目标配置，先使用目标配置自身的模板渲染消息
+func sendToTarget(ctx context.Context, configName string, params map[string]string) (string, error) {
+	config, exists := getConfigManager().GetConfig(configName)
+	if !exists {
+		return "", fmt.Errorf("配置 '%s' 不存在", configName)
+	}
+
+	params = copyParams(params)
+	if err := applyTemplates(config, params, nil); err != nil {
+		return "", err
+	}
+	return sendToConfig(ctx, configName, params)
+}
+
+// invalidParamError 请求参数错误，重试也无法成功，响应 400 且不加入重试队列
+type invalidParamError struct {
+	err error
+}
+
+func (e *invalidParamError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidParamError) Unwrap() error {
+	return e.err
+}
+
+// invalidParamf 创建请求参数错误
+func invalidParamf(format string, args ...interface{}) error {
+	return &invalidParamError{err: fmt.Errorf(format, args...)}
+}
+
+// isInvalidParam 判断错误是否为请求参数错误
+func isInvalidParam(err error) bool {
+	var paramErr *invalidParamError
+	return errors.As(err, &paramErr)
+}
+
+// partialSendError 消息已部分送达，加入重试队列时合并 Resume 中的进度参数，重试只发送剩余部分
+type partialSendError struct {
+	err    error
+	Resume map[string]string
+}
+
+func (e *partialSendError) Error() string {
+	return e.err.Error()
+}
+
+func (e *partialSendError) Unwrap() error {
+	return e.err
+}
+
+// retryParams 返回加入重试队列的参数，已部分送达的消息带上发送进度，避免重复发送已送达的部分
+func retryParams(params map[string]string, err error) map[string]string {
+	var partial *partialSendError
+	if !errors.As(err, &partial) {
+		return params
+	}
+	params = copyParams(params)
+	for key, value := range partial.Resume {
+		params[key] = value
+	}
+	return params
+}
+
+// copyParams 复制请求参数，避免并发发送时相互修改
+func copyParams(params map[string]string) map[string]string {
+	copied := make(map[string]string, len(params))
+	for key, value := range params {
+		copied[key] = value
+	}
+	return copied
+}
+
+// rateLimitQueueKey 上下文中禁用 queue 模式限流的标记
+type rateLimitQueueKey struct{}
+
+// withoutRateLimitQueue 返回不暂存超限消息的上下文，queue 模式的限流按 reject 模式处理，
+// 供需要立即得知发送结果的故障转移使用
+func withoutRateLimitQueue(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitQueueKey{}, true)
+}
+
+// delayedOrigin 限流暂存消息的来源，决定延后发送失败时如何重试
+type delayedOrigin struct {
+	Job   *retryJob    // 来自重试队列的原任务，失败后放回队列并保留已重试次数和创建时间
+	Group *groupTarget // 来自群发的目标，失败后按群发的规则重试该目标
+}
+
+// sendDelayed 等待限流结束后发送暂存的消息，失败时按配置加入重试队列
+func sendDelayed(configName string, config PushConfig, channel Channel, params map[string]string, wait time.Duration, held []*tokenBucket, origin delayedOrigin) {
+	time.Sleep(wait)
+	for _, bucket := range held {
+		bucket.release()
+	}
+
+	result, err := channel.Send(context.Background(), configName, config.Config, params)
+	if err == nil {
+		fmt.Printf("[%s] %s - 限流暂存消息发送完成: %s\n", timestamp(), configName, result)
+		return
+	}
+	if origin.Job != nil {
+		retryQueue.Requeue(origin.Job, err)
+		return
+	}
+
+	ts := timestamp()
+	errorMsg := fmt.Sprintf("限流暂存消息发送失败: %v", err)
+	writeErrorLog(ts, configName, config.Type, errorMsg, params)
+	fmt.Printf("[%s] %s - %s\n", ts, configName, errorMsg)
+
+	if origin.Group != nil {
+		enqueueGroupTarget(origin.Group.Group, configName, origin.Group.Params, err)
+		return
+	}
+	if _, isGroup := asGroupError(err); config.Retry != nil && !isGroup && !isInvalidParam(err) {
+		if _, err := retryQueue.Enqueue(configName, config.Retry, params, err); err != nil {
+			fmt.Printf("[%s] %s - 加入重试队列失败: %v\n", ts, configName, err)
+		}
+	}
+}
@@ -6,20 +6,65 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 )
 
 // PushConfig 推送配置结构
 type PushConfig struct {
-	Type   string                 `json:"type"`
-	Config map[string]interface{} `json:"config"`
+	Type      string                 `json:"type"`
+	Config    map[string]interface{} `json:"config"`
+	Retry     *RetryConfig           `json:"retry"`
+	Auth      *AuthConfig            `json:"auth"`
+	Signature *SignatureConfig       `json:"signature"`
+	IPFilter  *IPFilterConfig        `json:"ip_filter"`
+	RateLimit *RateLimitConfig       `json:"rate_limit"`
+	Dedup     *DedupConfig           `json:"dedup"`
+	Digest    *DigestConfig          `json:"digest"`
+
+	QuietHours *QuietHoursConfig `json:"quiet_hours"`
+
+	Template      string `json:"template"`       // 消息模板（text/template 语法）
+	TitleTemplate string `json:"title_template"` // 标题模板
+
+	Source  string         `json:"source"`  // webhook 来源，例如 alertmanager
+	Forge   *ForgeConfig   `json:"forge"`   // 代码托管平台 webhook 的签名校验和事件过滤
+	Mapping *MappingConfig `json:"mapping"` // 通用 JSON webhook 的字段映射，设置后来源默认为 json
+}
+
+// RetryConfig 发送失败后的重试配置，未配置时失败消息不会进入重试队列
+type RetryConfig struct {
+	MaxAttempts int `json:"max_attempts"` // 最大重试次数
+	MaxAge      int `json:"max_age"`      // 消息最长保留时间（秒）
+	BaseDelay   int `json:"base_delay"`   // 首次重试间隔（秒），之后按指数退避
+	MaxDelay    int `json:"max_delay"`    // 重试间隔上限（秒）
 }
 
 // ConfigManager 配置管理器
 type ConfigManager struct {
-	Route             string `json:"route"`
-	HeartbeatURL      string `json:"heartbeat_url"`
-	HeartbeatInterval int    `json:"heartbeat_interval"`
-	Configs           map[string]PushConfig
+	Route               string          `json:"route"`
+	HeartbeatURL        string          `json:"heartbeat_url"`
+	HeartbeatInterval   int             `json:"heartbeat_interval"`
+	ConfigWatchInterval int             `json:"config_watch_interval"`
+	Auth                *AuthConfig     `json:"auth"`
+	TrustedProxies      []string        `json:"trusted_proxies"`
+	IPFilter            *IPFilterConfig `json:"ip_filter"`
+	RateLimits          map[string]*RateLimitConfig
+	MaxBodySize         int64 `json:"max_body_size"`
+	Configs             map[string]PushConfig
+}
+
+// globalConfigKeys 配置文件中的全局字段，其余字段均视为推送配置
+var globalConfigKeys = map[string]bool{
+	"route":                 true,
+	"heartbeat_url":         true,
+	"heartbeat_interval":    true,
+	"config_watch_interval": true,
+	"auth":                  true,
+	"trusted_proxies":       true,
+	"ip_filter":             true,
+	"rate_limits":           true,
+	"max_body_size":         true,
 }
 
 // NewConfigManager 创建配置管理器
@@ -59,43 +104,202 @@ func NewConfigManager(configFile string) (*ConfigManager, error) {
 		heartbeatInterval = int(intervalValue)
 	}
 
-	// 提取推送配置（排除route、heartbeat_url、heartbeat_interval字段）
+	// 提取配置文件变更检测间隔
+	configWatchInterval := 0
+	if intervalValue, ok := rawConfig["config_watch_interval"].(float64); ok {
+		configWatchInterval = int(intervalValue)
+	}
+
+	// 提取全局鉴权配置
+	var auth *AuthConfig
+	if err := decodeConfigValue(rawConfig["auth"], &auth); err != nil {
+		return nil, fmt.Errorf("解析全局鉴权配置失败: %v", err)
+	}
+
+	// 提取可信代理和全局IP访问控制配置
+	var trustedProxies []string
+	if err := decodeConfigValue(rawConfig["trusted_proxies"], &trustedProxies); err != nil {
+		return nil, fmt.Errorf("解析可信代理配置失败: %v", err)
+	}
+
+	var ipFilter *IPFilterConfig
+	if err := decodeConfigValue(rawConfig["ip_filter"], &ipFilter); err != nil {
+		return nil, fmt.Errorf("解析全局IP访问控制配置失败: %v", err)
+	}
+
+	// 提取按推送类型的限流配置
+	var rateLimits map[string]*RateLimitConfig
+	if err := decodeConfigValue(rawConfig["rate_limits"], &rateLimits); err != nil {
+		return nil, fmt.Errorf("解析限流配置失败: %v", err)
+	}
+
+	// 提取请求体大小上限
+	var maxBodySize int64
+	if sizeValue, ok := rawConfig["max_body_size"].(float64); ok {
+		maxBodySize = int64(sizeValue)
+	}
+
+	// 提取推送配置（排除全局字段）
 	configs := make(map[string]PushConfig)
 	for key, value := range rawConfig {
-		if key != "route" && key != "heartbeat_url" && key != "heartbeat_interval" {
-			// 将interface{}转换为PushConfig
-			valueBytes, err := json.Marshal(value)
-			if err != nil {
-				continue
-			}
+		if !globalConfigKeys[key] {
+			// 将interface{}转换为PushConfig，解析失败时整个配置文件无效，热重载时继续使用原配置
 			var pushConfig PushConfig
-			err = json.Unmarshal(valueBytes, &pushConfig)
-			if err != nil {
-				continue
+			if err := decodeConfigValue(value, &pushConfig); err != nil {
+				return nil, fmt.Errorf("解析配置 '%s' 失败: %v", key, err)
 			}
 			configs[key] = pushConfig
 		}
 	}
 
 	return &ConfigManager{
-		Route:             route,
-		HeartbeatURL:      heartbeatURL,
-		HeartbeatInterval: heartbeatInterval,
-		Configs:           configs,
+		Route:               route,
+		HeartbeatURL:        heartbeatURL,
+		HeartbeatInterval:   heartbeatInterval,
+		ConfigWatchInterval: configWatchInterval,
+		Auth:                auth,
+		TrustedProxies:      trustedProxies,
+		IPFilter:            ipFilter,
+		RateLimits:          rateLimits,
+		MaxBodySize:         maxBodySize,
+		Configs:             configs,
 	}, nil
 }
 
+// decodeConfigValue 将通用配置值转换为指定结构，值不存在时不做任何处理
+func decodeConfigValue(value interface{}, v interface{}) error {
+	if value == nil {
+		return nil
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(valueBytes, v)
+}
+
 // GetConfig 获取指定名称的配置
 func (cm *ConfigManager) GetConfig(name string) (PushConfig, bool) {
 	config, exists := cm.Configs[name]
 	return config, exists
 }
 
-// GetAllConfigNames 获取所有配置名称
+// Validate 校验所有推送配置的类型和参数
+func (cm *ConfigManager) Validate() error {
+	for _, name := range cm.GetAllConfigNames() {
+		config := cm.Configs[name]
+		channel, exists := GetChannel(config.Type)
+		if !exists {
+			return fmt.Errorf("配置 '%s' 的推送类型不支持: %s", name, config.Type)
+		}
+		if err := channel.Validate(config.Config); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if config.Signature != nil && config.Signature.Secret == "" {
+			return fmt.Errorf("配置 '%s' 无效: 签名校验缺少 secret", name)
+		}
+		if err := config.IPFilter.validate(); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if err := config.RateLimit.validate(); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if err := config.QuietHours.validate(); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if err := config.Digest.validate(); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if err := config.Dedup.validate(); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if err := config.validateTemplates(); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if _, exists := GetWebhookAdapter(config.Source); config.Source != "" && !exists {
+			return fmt.Errorf("配置 '%s' 无效: 不支持的 webhook 来源: %s", name, config.Source)
+		}
+		if err := config.Forge.validate(config.Source); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+		if err := config.Mapping.validate(config.Source); err != nil {
+			return fmt.Errorf("配置 '%s' 无效: %v", name, err)
+		}
+	}
+
+	// 检查按推送类型的限流配置
+	for typeName, rateLimit := range cm.RateLimits {
+		if _, exists := GetChannel(typeName); !exists {
+			return fmt.Errorf("限流配置的推送类型不支持: %s", typeName)
+		}
+		if err := rateLimit.validate(); err != nil {
+			return fmt.Errorf("推送类型 '%s' 的限流配置无效: %v", typeName, err)
+		}
+	}
+
+	// 检查心跳检测配置，间隔不大于 0 时无法创建定时器
+	if cm.HeartbeatURL != "" && cm.HeartbeatInterval <= 0 {
+		return fmt.Errorf("心跳检测配置无效: 设置了 heartbeat_url 时 heartbeat_interval 必须大于 0")
+	}
+
+	// 检查可信代理和全局IP访问控制
+	if _, err := parsePrefixes(cm.TrustedProxies); err != nil {
+		return fmt.Errorf("可信代理配置无效: %v", err)
+	}
+	if err := cm.IPFilter.validate(); err != nil {
+		return fmt.Errorf("全局IP访问控制配置无效: %v", err)
+	}
+
+	// 检查引用其他配置的推送类型
+	for _, name := range cm.GetAllConfigNames() {
+		if err := cm.checkReferences(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReferences 检查配置引用的目标是否存在以及是否存在循环引用
+func (cm *ConfigManager) checkReferences(name string, path []string) error {
+	for _, visited := range path {
+		if visited == name {
+			return fmt.Errorf("配置存在循环引用: %s -> %s", strings.Join(path, " -> "), name)
+		}
+	}
+
+	config := cm.Configs[name]
+	channel, _ := GetChannel(config.Type)
+	refChannel, ok := channel.(ReferenceChannel)
+	if !ok {
+		return nil
+	}
+
+	path = append(path, name)
+	for _, target := range refChannel.References(config.Config) {
+		if _, exists := cm.Configs[target]; !exists {
+			return fmt.Errorf("配置 '%s' 引用的配置 '%s' 不存在", name, target)
+		}
+		if err := cm.checkReferences(target, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxBodySize 返回请求体大小上限，未配置时使用默认值
+func (cm *ConfigManager) maxBodySize() int64 {
+	if cm.MaxBodySize > 0 {
+		return cm.MaxBodySize
+	}
+	return defaultMaxBodySize
+}
+
+// GetAllConfigNames 获取所有配置名称（按名称排序）
 func (cm *ConfigManager) GetAllConfigNames() []string {
 	names := make([]string, 0, len(cm.Configs))
 	for name := range cm.Configs {
 		names = append(names, name)
 	}
+	sort.Strings(names)
 	return names
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 窗口结束后仍未再次发送的重复计数最长保留时间
+const dedupCountRetention = 24 * time.Hour
+
+// DedupConfig 重复消息抑制配置
+type DedupConfig struct {
+	Window      int  `json:"window"`       // 去重时间窗口（秒），窗口内相同的消息只发送一次
+	ShowRepeats bool `json:"show_repeats"` // 窗口结束后发送的消息末尾附带被抑制的次数
+}
+
+// validate 检查去重配置
+func (c *DedupConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("去重配置 window 必须大于 0")
+	}
+	return nil
+}
+
+// dedupEntry 去重记录
+type dedupEntry struct {
+	expiresAt  time.Time // 去重窗口结束时间
+	suppressed int       // 当前窗口内被抑制的次数
+}
+
+// dedupCache 已发送消息的去重记录
+type dedupCache struct {
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// 全局去重记录
+var dedup = &dedupCache{entries: make(map[string]*dedupEntry)}
+
+// dedupKey 计算去重键，优先使用调用方提供的 dedup_key，否则使用配置名、标题、消息内容和上传的文件
+func dedupKey(configName string, params map[string]string) string {
+	// 上传文件以内容的 SHA-256 区分，说明文字相同的不同截图不会被当作重复消息
+	content := params["title"] + "\n" + params["msg"] + "\n" + params["files"]
+	if key := params["dedup_key"]; key != "" {
+		content = "key:" + key
+	}
+	sum := sha256.Sum256([]byte(configName + "\n" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// check 检查消息是否在去重窗口内重复
+// 重复时累加抑制次数并返回 suppressed 为 true 及本窗口已抑制的次数；否则开始新的窗口，返回上个窗口被抑制的次数
+func (c *dedupCache) check(key string, window time.Duration) (suppressed bool, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) && (entry.suppressed == 0 || now.After(entry.expiresAt.Add(dedupCountRetention))) {
+			delete(c.entries, k)
+		}
+	}
+
+	entry, exists := c.entries[key]
+	if exists && now.Before(entry.expiresAt) {
+		entry.suppressed++
+		return true, entry.suppressed
+	}
+	if exists {
+		count = entry.suppressed
+	}
+	c.entries[key] = &dedupEntry{expiresAt: now.Add(window)}
+	return false, count
+}
+
+// release 消息发送失败时结束去重窗口，使下一条相同消息可以发送，并保留被抑制的次数
+func (c *dedupCache) release(key string, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &dedupEntry{expiresAt: time.Now(), suppressed: count}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 汇总消息的默认标题
+const defaultDigestTitle = "消息汇总"
+
+// DigestConfig 消息汇总配置，消息先暂存，到达时间窗口或数量、大小上限时合并为一条发送
+type DigestConfig struct {
+	Window   int    `json:"window"`    // 汇总周期（秒），从第一条消息暂存时开始计算
+	MaxCount int    `json:"max_count"` // 暂存消息数达到后立即发送，0 表示不限制
+	MaxBytes int    `json:"max_bytes"` // 暂存消息总字节数达到后立即发送，0 表示不限制
+	Title    string `json:"title"`     // 汇总消息标题，默认为"消息汇总"
+}
+
+// validate 检查汇总配置
+func (c *DigestConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("汇总配置 window 必须大于 0")
+	}
+	if c.MaxCount < 0 || c.MaxBytes < 0 {
+		return fmt.Errorf("汇总配置 max_count 和 max_bytes 不能为负数")
+	}
+	return nil
+}
+
+// digestItem 暂存的单条消息
+type digestItem struct {
+	Time  time.Time `json:"time"`
+	Title string    `json:"title"`
+	Msg   string    `json:"msg"`
+}
+
+// digestBatch 同一配置在一个汇总周期内暂存的消息
+type digestBatch struct {
+	ID         string       `json:"id"`
+	ConfigName string       `json:"config_name"`
+	CreatedAt  time.Time    `json:"created_at"`
+	FlushAt    time.Time    `json:"flush_at"`
+	Size       int          `json:"size"`
+	Items      []digestItem `json:"items"`
+
+	flushing bool // 正在发送，新消息进入下一批
+}
+
+// DigestQueue 持久化的消息汇总队列
+type DigestQueue struct {
+	mu      sync.Mutex
+	file    string
+	batches []*digestBatch
+}
+
+// 全局消息汇总队列
+var digestQueue *DigestQueue
+
+// NewDigestQueue 创建消息汇总队列并加载上次未发送的消息
+func NewDigestQueue(file string) (*DigestQueue, error) {
+	q := &DigestQueue{file: file}
+	if err := loadJSONFile(file, &q.batches); err != nil {
+		return nil, fmt.Errorf("读取汇总队列失败: %v", err)
+	}
+	return q, nil
+}
+
+// Len 返回队列中暂存的消息数量
+func (q *DigestQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := 0
+	for _, batch := range q.batches {
+		count += len(batch.Items)
+	}
+	return count
+}
+
+// Add 暂存一条消息，返回当前批次的消息数；达到数量或大小上限时立即发送该批次
+func (q *DigestQueue) Add(configName string, digest *DigestConfig, params map[string]string) (int, error) {
+	flushAt := time.Now().Add(time.Duration(digest.Window) * time.Second)
+	return q.add(configName, flushAt, digest.MaxCount, digest.MaxBytes, params)
+}
+
+// Hold 暂存免打扰时段内的消息，批次最早在 until 发送，不受数量和大小上限限制
+func (q *DigestQueue) Hold(configName string, until time.Time, params map[string]string) (int, error) {
+	return q.add(configName, until, 0, 0, params)
+}
+
+// add 将消息加入配置当前的批次，没有批次时新建一个在 flushAt 发送的批次
+func (q *DigestQueue) add(configName string, flushAt time.Time, maxCount, maxBytes int, params map[string]string) (int, error) {
+	now := time.Now()
+	item := digestItem{Time: now, Title: params["title"], Msg: params["msg"]}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var batch *digestBatch
+	for _, b := range q.batches {
+		if b.ConfigName == configName && !b.flushing {
+			batch = b
+		}
+	}
+	created := batch == nil
+	if created {
+		batch = &digestBatch{
+			ID:         newID(),
+			ConfigName: configName,
+			CreatedAt:  now,
+			FlushAt:    flushAt,
+		}
+		q.batches = append(q.batches, batch)
+	}
+	batch.Items = append(batch.Items, item)
+	batch.Size += len(item.Title) + len(item.Msg)
+
+	if err := q.save(); err != nil {
+		batch.Items = batch.Items[:len(batch.Items)-1]
+		batch.Size -= len(item.Title) + len(item.Msg)
+		if created {
+			q.batches = q.batches[:len(q.batches)-1]
+		}
+		return 0, err
+	}
+
+	count := len(batch.Items)
+	if (maxCount > 0 && count >= maxCount) || (maxBytes > 0 && batch.Size >= maxBytes) {
+		batch.flushing = true
+		go q.flush(batch)
+	}
+	return count, nil
+}
+
+// Start 启动后台汇总任务
+func (q *DigestQueue) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			q.processDue()
+		}
+	}()
+}
+
+// processDue 发送所有已到汇总时间的批次，处于免打扰时段的批次推迟到时段结束
+func (q *DigestQueue) processDue() {
+	now := time.Now()
+
+	q.mu.Lock()
+	var due []*digestBatch
+	for _, batch := range q.batches {
+		if batch.flushing || batch.FlushAt.After(now) {
+			continue
+		}
+		if config, exists := getConfigManager().GetConfig(batch.ConfigName); exists && config.QuietHours != nil {
+			if until, active := config.QuietHours.activeUntil(now); active {
+				batch.FlushAt = until
+				continue
+			}
+		}
+		batch.flushing = true
+		due = append(due, batch)
+	}
+	q.mu.Unlock()
+
+	for _, batch := range due {
+		q.flush(batch)
+	}
+}
+
+// flush 将批次合并为汇总消息发送，发送失败时按配置加入重试队列
+func (q *DigestQueue) flush(batch *digestBatch) {
+	defer q.remove(batch.ID)
+
+	config, exists := getConfigManager().GetConfig(batch.ConfigName)
+	if !exists {
+		ts := timestamp()
+		errorMsg := fmt.Sprintf("配置不存在，丢弃 %d 条汇总消息", len(batch.Items))
+		writeErrorLog(ts, batch.ConfigName, "unknown", errorMsg, nil)
+		fmt.Printf("[%s] %s - %s\n", ts, batch.ConfigName, errorMsg)
+		return
+	}
+
+	title := defaultDigestTitle
+	if config.Digest != nil && config.Digest.Title != "" {
+		title = config.Digest.Title
+	}
+
+	for _, params := range digestMessages(batch, title, maxMessageLength(batch.ConfigName)) {
+		result, err := sendToConfig(context.Background(), batch.ConfigName, params)
+		if err == nil {
+			fmt.Printf("[%s] %s - 汇总发送 %d 条消息: %s\n", timestamp(), batch.ConfigName, len(batch.Items), result)
+			continue
+		}
+
+		ts := timestamp()
+		errorMsg := fmt.Sprintf("汇总消息发送失败: %v", err)
+		writeErrorLog(ts, batch.ConfigName, config.Type, errorMsg, params)
+		if _, isGroup := asGroupError(err); config.Retry != nil && !isGroup && !isInvalidParam(err) {
+			if jobID, queueErr := retryQueue.Enqueue(batch.ConfigName, config.Retry, params, err); queueErr == nil {
+				errorMsg += " - Queued: " + jobID
+			}
+		}
+		fmt.Printf("[%s] %s - %s\n", ts, batch.ConfigName, errorMsg)
+	}
+}
+
+// digestMessages 将批次中的消息合并为一条或多条（超过渠道长度上限时拆分）汇总消息
+func digestMessages(batch *digestBatch, title string, maxLength int) []map[string]string {
+	title = fmt.Sprintf("%s (%d 条)", title, len(batch.Items))
+
+	blocks := make([]string, len(batch.Items))
+	for i, item := range batch.Items {
+		block := "[" + item.Time.Format("15:04:05") + "]"
+		if item.Title != "" {
+			block += " " + item.Title
+		}
+		blocks[i] = block + "\n" + item.Msg
+	}
+
+	// 汇总消息不丢弃任何内容，拆分条数不设上限
+	parts := splitMessage(title+"\n\n", blocks, maxLength, len(blocks))
+	messages := make([]map[string]string, len(parts))
+	for i, part := range parts {
+		partTitle := title
+		if len(parts) > 1 {
+			partTitle = fmt.Sprintf("%s (%d/%d)", title, i+1, len(parts))
+			part = fmt.Sprintf("(%d/%d) %s", i+1, len(parts), part)
+		}
+		messages[i] = map[string]string{
+			"title": partTitle,
+			"msg":   strings.TrimRight(part, "\n"),
+		}
+	}
+	return messages
+}
+
+// remove 从队列中移除批次
+func (q *DigestQueue) remove(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, batch := range q.batches {
+		if batch.ID == id {
+			q.batches = append(q.batches[:i], q.batches[i+1:]...)
+			break
+		}
+	}
+	if err := q.save(); err != nil {
+		fmt.Printf("[%s] 保存汇总队列失败: %v\n", timestamp(), err)
+	}
+}
+
+// save 将队列写入文件，调用方需持有锁
+func (q *DigestQueue) save() error {
+	batches := q.batches
+	if batches == nil {
+		batches = []*digestBatch{}
+	}
+	return saveJSONFile(q.file, batches)
+}
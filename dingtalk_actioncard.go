@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// 只提供 url 参数时单个按钮的默认文字
+const defaultDingTalkButtonTitle = "查看详情"
+
+// dingTalkButton 卡片按钮
+type dingTalkButton struct {
+	Title     string `json:"title"`
+	ActionURL string `json:"actionURL"`
+}
+
+// dingTalkActionCardMessage 钉钉卡片消息结构，单个按钮使用 SingleTitle/SingleURL，多个按钮使用 Btns
+type dingTalkActionCardMessage struct {
+	Title          string           `json:"title"`
+	Text           string           `json:"text"`
+	BtnOrientation string           `json:"btnOrientation,omitempty"`
+	SingleTitle    string           `json:"singleTitle,omitempty"`
+	SingleURL      string           `json:"singleURL,omitempty"`
+	Btns           []dingTalkButton `json:"btns,omitempty"`
+}
+
+// dingTalkActionCardRequest 发送卡片消息的请求结构
+type dingTalkActionCardRequest struct {
+	MsgType    string                    `json:"msgtype"`
+	ActionCard dingTalkActionCardMessage `json:"actionCard"`
+}
+
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("dingtalk_actioncard",
+		dingTalkRobotSchema(),
+		func(configData map[string]interface{}) error {
+			_, err := convertToDingTalkTextConfig(configData)
+			return err
+		},
+		SendDingTalkActionCard,
+	), 20000))
+}
+
+// SendDingTalkActionCard 发送钉钉卡片消息 - 统一接口
+// 按钮通过 buttons 参数（JSON 数组，每项包含 title 和 url）提供，或通过 url 和 button_title 参数提供单个按钮
+func SendDingTalkActionCard(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	// 转换配置
+	config, err := convertToDingTalkTextConfig(configData)
+	if err != nil {
+		return "", err
+	}
+
+	buttons, err := parseDingTalkButtons(params)
+	if err != nil {
+		return "", err
+	}
+
+	// 构造请求数据
+	card := dingTalkActionCardMessage{
+		Title: dingTalkTitle(params),
+		Text:  params["msg"],
+	}
+	if params["btn_orientation"] == "1" {
+		card.BtnOrientation = "1"
+	}
+	if len(buttons) == 1 {
+		card.SingleTitle = buttons[0].Title
+		card.SingleURL = buttons[0].ActionURL
+	} else {
+		card.Btns = buttons
+	}
+
+	requestData := dingTalkActionCardRequest{
+		MsgType:    "actionCard",
+		ActionCard: card,
+	}
+
+	return sendDingTalkRobotMessage(ctx, configName, "钉钉卡片", config, requestData)
+}
+
+// parseDingTalkButtons 解析卡片按钮参数，至少需要一个按钮
+func parseDingTalkButtons(params map[string]string) ([]dingTalkButton, error) {
+	if params["buttons"] == "" {
+		if params["url"] == "" {
+			return nil, invalidParamf("钉钉卡片消息缺少buttons或url参数")
+		}
+		title := params["button_title"]
+		if title == "" {
+			title = defaultDingTalkButtonTitle
+		}
+		return []dingTalkButton{{Title: title, ActionURL: params["url"]}}, nil
+	}
+
+	var items []struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(params["buttons"]), &items); err != nil {
+		return nil, invalidParamf("buttons参数格式错误，应为包含 title 和 url 的JSON数组: %v", err)
+	}
+	if len(items) == 0 {
+		return nil, invalidParamf("buttons参数不能为空")
+	}
+
+	buttons := make([]dingTalkButton, len(items))
+	for i, item := range items {
+		if item.Title == "" || item.URL == "" {
+			return nil, invalidParamf("buttons参数第 %d 个按钮缺少 title 或 url", i+1)
+		}
+		buttons[i] = dingTalkButton{Title: item.Title, ActionURL: item.URL}
+	}
+	return buttons, nil
+}
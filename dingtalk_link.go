@@ -0,0 +1,54 @@
+package main
+
+import "context"
+
+// dingTalkLinkMessage 钉钉链接消息结构
+type dingTalkLinkMessage struct {
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+	PicURL     string `json:"picUrl,omitempty"`
+	MessageURL string `json:"messageUrl"`
+}
+
+// dingTalkLinkRequest 发送链接消息的请求结构
+type dingTalkLinkRequest struct {
+	MsgType string              `json:"msgtype"`
+	Link    dingTalkLinkMessage `json:"link"`
+}
+
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("dingtalk_link",
+		dingTalkRobotSchema(),
+		func(configData map[string]interface{}) error {
+			_, err := convertToDingTalkTextConfig(configData)
+			return err
+		},
+		SendDingTalkLink,
+	), 20000))
+}
+
+// SendDingTalkLink 发送钉钉链接消息 - 统一接口，点击消息打开 url 参数指定的地址
+func SendDingTalkLink(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	// 转换配置
+	config, err := convertToDingTalkTextConfig(configData)
+	if err != nil {
+		return "", err
+	}
+
+	if params["url"] == "" {
+		return "", invalidParamf("钉钉链接消息缺少url参数")
+	}
+
+	// 构造请求数据
+	requestData := dingTalkLinkRequest{
+		MsgType: "link",
+		Link: dingTalkLinkMessage{
+			Title:      dingTalkTitle(params),
+			Text:       params["msg"],
+			PicURL:     params["pic_url"],
+			MessageURL: params["url"],
+		},
+	}
+
+	return sendDingTalkRobotMessage(ctx, configName, "钉钉链接", config, requestData)
+}
@@ -0,0 +1,50 @@
+package main
+
+import "context"
+
+// dingTalkMarkdownMessage 钉钉 Markdown 消息结构
+type dingTalkMarkdownMessage struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// dingTalkMarkdownRequest 发送 Markdown 消息的请求结构
+type dingTalkMarkdownRequest struct {
+	MsgType  string                  `json:"msgtype"`
+	Markdown dingTalkMarkdownMessage `json:"markdown"`
+	At       *dingTalkAt             `json:"at,omitempty"`
+}
+
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("dingtalk_markdown",
+		append(dingTalkRobotSchema(), mentionSchema()...),
+		func(configData map[string]interface{}) error {
+			_, err := convertToDingTalkTextConfig(configData)
+			return err
+		},
+		SendDingTalkMarkdown,
+	), 20000))
+}
+
+// SendDingTalkMarkdown 发送钉钉 Markdown 消息 - 统一接口
+func SendDingTalkMarkdown(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	// 转换配置
+	config, err := convertToDingTalkTextConfig(configData)
+	if err != nil {
+		return "", err
+	}
+
+	text, at := dingTalkMention(params["msg"], config.Mention.withParams(params))
+
+	// 构造请求数据，title 只显示在会话列表和通知中
+	requestData := dingTalkMarkdownRequest{
+		MsgType: "markdown",
+		Markdown: dingTalkMarkdownMessage{
+			Title: dingTalkTitle(params),
+			Text:  text,
+		},
+		At: at,
+	}
+
+	return sendDingTalkRobotMessage(ctx, configName, "钉钉Markdown", config, requestData)
+}
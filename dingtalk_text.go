@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,6 +14,8 @@ import (
 type DingTalkTextConfig struct {
 	AccessToken string
 	APIBaseURL  string
+	Secret      string // 加签密钥（可选），机器人安全设置为"加签"时必填
+	Mention     mentionConfig
 }
 
 // dingTalkTextMessage 钉钉文本消息结构
@@ -17,25 +23,42 @@ type dingTalkTextMessage struct {
 	Content string `json:"content"`
 }
 
+// dingTalkAt 消息的提醒对象
+type dingTalkAt struct {
+	AtMobiles []string `json:"atMobiles,omitempty"`
+	AtUserIds []string `json:"atUserIds,omitempty"`
+	IsAtAll   bool     `json:"isAtAll,omitempty"`
+}
+
 // dingTalkTextRequest 发送文本消息的请求结构
 type dingTalkTextRequest struct {
 	MsgType string              `json:"msgtype"`
 	Text    dingTalkTextMessage `json:"text"`
+	At      *dingTalkAt         `json:"at,omitempty"`
+}
+
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("dingtalk_text",
+		append(dingTalkRobotSchema(), mentionSchema()...),
+		func(configData map[string]interface{}) error {
+			_, err := convertToDingTalkTextConfig(configData)
+			return err
+		},
+		SendDingTalkText,
+	), 20000))
 }
 
 // SendDingTalkText 发送钉钉文本消息 - 统一接口
-func SendDingTalkText(configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+func SendDingTalkText(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
 	// 转换配置
 	config, err := convertToDingTalkTextConfig(configData)
 	if err != nil {
 		return "", err
 	}
 
-	// 获取消息内容
-	message := params["msg"]
-
-	// 构造完整的Webhook URL
-	url := fmt.Sprintf("%s?access_token=%s", config.APIBaseURL, config.AccessToken)
+	// 获取消息内容和提醒对象
+	mention := config.Mention.withParams(params)
+	message, at := dingTalkMention(params["msg"], mention)
 
 	// 构造请求数据
 	requestData := dingTalkTextRequest{
@@ -43,21 +66,97 @@ func SendDingTalkText(configName string, configData map[string]interface{}, para
 		Text: dingTalkTextMessage{
 			Content: message,
 		},
+		At: at,
 	}
 
+	return sendDingTalkRobotMessage(ctx, configName, "钉钉文本", config, requestData)
+}
+
+// sendDingTalkRobotMessage 通过机器人 Webhook 发送消息，各钉钉消息类型共用
+func sendDingTalkRobotMessage(ctx context.Context, configName, platform string, config DingTalkTextConfig, requestData interface{}) (string, error) {
+	// 构造完整的Webhook URL
+	url := dingTalkWebhookURL(config.APIBaseURL, config.AccessToken, config.Secret)
+
 	jsonData, err := json.Marshal(requestData)
 	if err != nil {
 		return "", err
 	}
 
 	// 发送请求
-	response, err := httpRequest("POST", url, jsonData, 30*time.Second)
+	response, err := httpRequest(ctx, "POST", url, jsonData, 30*time.Second)
 	if err != nil {
 		return "", err
 	}
 
 	responseStr := string(response)
-	return handleAPIResponse(configName, "钉钉文本", responseStr, `"errcode":0`)
+	return handleDingTalkResponse(configName, platform, responseStr, config.Secret != "")
+}
+
+// dingTalkRobotSchema 钉钉机器人各消息类型共用的配置项
+func dingTalkRobotSchema() []ConfigField {
+	return []ConfigField{
+		{Name: "AccessToken", Required: true, Description: "机器人Webhook Token"},
+		{Name: "APIBaseURL", Required: true, Description: "钉钉机器人接口地址"},
+		{Name: "Secret", Description: "加签密钥（可选）"},
+	}
+}
+
+// dingTalkTitle 获取消息标题，未提供 title 参数时使用消息的第一行
+func dingTalkTitle(params map[string]string) string {
+	if title := strings.TrimSpace(params["title"]); title != "" {
+		return title
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(params["msg"]), "\n")
+	line = strings.TrimSpace(strings.TrimLeft(line, "#> "))
+	if line == "" {
+		return "通知"
+	}
+	return truncateBytes(line, 64)
+}
+
+// dingTalkMention 构造提醒对象，并将正文中缺少的 @手机号、@用户ID 追加到末尾（钉钉只高亮正文中出现的提醒对象）
+func dingTalkMention(content string, mention mentionConfig) (string, *dingTalkAt) {
+	if len(mention.AtMobiles) == 0 && len(mention.AtUserIds) == 0 && !mention.AtAll {
+		return content, nil
+	}
+	if handles := mention.handles(content); handles != "" {
+		content += "\n" + handles
+	}
+	return content, &dingTalkAt{
+		AtMobiles: mention.AtMobiles,
+		AtUserIds: mention.AtUserIds,
+		IsAtAll:   mention.AtAll,
+	}
+}
+
+// dingTalkWebhookURL 构造机器人 Webhook URL，设置了加签密钥时附带 timestamp 和 sign 参数
+func dingTalkWebhookURL(apiBaseURL, accessToken, secret string) string {
+	webhookURL := fmt.Sprintf("%s?access_token=%s", apiBaseURL, accessToken)
+	if secret == "" {
+		return webhookURL
+	}
+
+	// 签名为 Base64(HmacSHA256(timestamp + "\n" + secret))，密钥为 secret
+	ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	sign := computeSignature(secret, ts, secret)
+	return fmt.Sprintf("%s&timestamp=%s&sign=%s", webhookURL, ts, url.QueryEscape(sign))
+}
+
+// handleDingTalkResponse 处理机器人接口响应，加签被拒绝时返回更明确的错误信息
+func handleDingTalkResponse(configName, platform, responseStr string, signed bool) (string, error) {
+	result, err := handleAPIResponse(configName, platform, responseStr, `"errcode":0`)
+	if err == nil || !signed || !strings.Contains(responseStr, `"errcode":310000`) {
+		return result, err
+	}
+
+	switch {
+	case strings.Contains(responseStr, "timestamp"):
+		return "", fmt.Errorf("钉钉加签时间戳被拒绝，请检查服务器时间是否准确（与钉钉服务器相差不能超过 1 小时）: %s", responseStr)
+	case strings.Contains(responseStr, "sign"):
+		return "", fmt.Errorf("钉钉加签校验失败，请检查 Secret 是否与机器人安全设置一致: %s", responseStr)
+	}
+	return "", err
 }
 
 // convertToDingTalkTextConfig 将通用配置转换为钉钉文本配置
@@ -65,13 +164,21 @@ func convertToDingTalkTextConfig(config map[string]interface{}) (DingTalkTextCon
 	// 使用类型断言提取配置值
 	accessToken, _ := config["AccessToken"].(string)
 	apiBaseURL, _ := config["APIBaseURL"].(string)
+	secret, _ := config["Secret"].(string)
 
 	if accessToken == "" || apiBaseURL == "" {
 		return DingTalkTextConfig{}, fmt.Errorf("缺少必要的钉钉配置参数")
 	}
 
+	mention, err := convertToMentionConfig(config)
+	if err != nil {
+		return DingTalkTextConfig{}, err
+	}
+
 	return DingTalkTextConfig{
 		AccessToken: accessToken,
 		APIBaseURL:  apiBaseURL,
+		Secret:      secret,
+		Mention:     mention,
 	}, nil
 }
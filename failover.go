@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FailoverHop 故障转移链中的一跳
+type FailoverHop struct {
+	Name    string
+	Timeout int // 本跳超时时间（秒），0 表示不单独限制
+}
+
+// FailoverConfig 故障转移配置，按顺序尝试链中的推送配置直到发送成功
+type FailoverConfig struct {
+	Chain []FailoverHop
+}
+
+// failoverChannel 故障转移渠道，额外声明引用的配置供配置校验使用
+type failoverChannel struct {
+	Channel
+}
+
+// References 返回故障转移链引用的配置名称
+func (failoverChannel) References(configData map[string]interface{}) []string {
+	config, err := convertToFailoverConfig(configData)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(config.Chain))
+	for i, hop := range config.Chain {
+		names[i] = hop.Name
+	}
+	return names
+}
+
+func init() {
+	RegisterChannel(failoverChannel{NewChannel("failover",
+		[]ConfigField{
+			{Name: "Chain", Required: true, Description: "按顺序尝试的配置列表，每项包含 Name 和可选的 Timeout（秒）"},
+		},
+		func(configData map[string]interface{}) error {
+			_, err := convertToFailoverConfig(configData)
+			return err
+		},
+		SendFailover,
+	)})
+}
+
+// SendFailover 按顺序尝试故障转移链中的配置 - 统一接口
+func SendFailover(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	config, err := convertToFailoverConfig(configData)
+	if err != nil {
+		return "", err
+	}
+
+	// 群发作为其中一跳失败时不单独重试失败的目标，由故障转移继续尝试下一跳；
+	// 限流时不暂存延后发送，按失败处理，避免未确认送达的一跳被当作成功
+	ctx = withoutRateLimitQueue(withoutGroupRetry(ctx))
+
+	failures := make([]string, 0, len(config.Chain))
+	invalid := true
+	for i, hop := range config.Chain {
+		result, err := sendWithTimeout(ctx, hop.Name, params, time.Duration(hop.Timeout)*time.Second)
+		if err == nil {
+			fmt.Printf("[%s] %s - 第%d跳 %s 发送成功\n", timestamp(), configName, i+1, hop.Name)
+			return fmt.Sprintf("%s (第%d跳 %s)", result, i+1, hop.Name), nil
+		}
+
+		fmt.Printf("[%s] %s - 第%d跳 %s 发送失败: %v\n", timestamp(), configName, i+1, hop.Name, err)
+		failures = append(failures, fmt.Sprintf("%s: %v", hop.Name, err))
+		invalid = invalid && isInvalidParam(err)
+	}
+
+	// 每一跳都因请求参数错误失败时，重试整个故障转移链也无法成功
+	if invalid {
+		return "", invalidParamf("故障转移链全部失败\n%s", strings.Join(failures, "\n"))
+	}
+	return "", fmt.Errorf("故障转移链全部失败\n%s", strings.Join(failures, "\n"))
+}
+
+// sendWithTimeout 发送消息，超时后取消正在进行的请求，避免超时的一跳在后台继续送达造成重复，timeout 为 0 时不限制
+func sendWithTimeout(ctx context.Context, configName string, params map[string]string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return sendToTarget(ctx, configName, params)
+	}
+
+	hopCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := sendToTarget(hopCtx, configName, params)
+	if err != nil && errors.Is(hopCtx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("发送超时 (%s)", timeout)
+	}
+	return result, err
+}
+
+// convertToFailoverConfig 将通用配置转换为故障转移配置
+func convertToFailoverConfig(config map[string]interface{}) (FailoverConfig, error) {
+	chainInterface, ok := config["Chain"].([]interface{})
+	if !ok || len(chainInterface) == 0 {
+		return FailoverConfig{}, fmt.Errorf("缺少 Chain 配置")
+	}
+
+	chain := make([]FailoverHop, len(chainInterface))
+	for i, hopInterface := range chainInterface {
+		switch hop := hopInterface.(type) {
+		case string:
+			chain[i] = FailoverHop{Name: hop}
+		case map[string]interface{}:
+			name, _ := hop["Name"].(string)
+			timeout, _ := hop["Timeout"].(float64)
+			chain[i] = FailoverHop{Name: name, Timeout: int(timeout)}
+		}
+		if chain[i].Name == "" {
+			return FailoverConfig{}, fmt.Errorf("配置 Chain 格式错误")
+		}
+	}
+
+	return FailoverConfig{Chain: chain}, nil
+}
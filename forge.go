@@ -0,0 +1,241 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// 提交列表中最多显示的提交数
+const maxForgeCommits = 10
+
+// forgeSources 代码托管平台 webhook 来源
+var forgeSources = map[string]bool{
+	"github": true,
+	"gitea":  true,
+	"gitlab": true,
+}
+
+// ForgeConfig 代码托管平台（GitHub、Gitea、GitLab）webhook 配置
+type ForgeConfig struct {
+	Secret   string   `json:"secret"`   // webhook 密钥，为空时不校验签名
+	Events   []string `json:"events"`   // 推送的事件，例如 push、pull_request.merged，为空时推送全部支持的事件
+	Branches []string `json:"branches"` // 推送的分支，支持通配符，例如 release/*，为空时不限制
+}
+
+// forgeEvent 由代码托管平台 webhook 转换得到的事件
+type forgeEvent struct {
+	Event      string // 统一的事件名称: push、tag、pull_request、issues、release、pipeline
+	Action     string // 事件动作，例如 opened、merged、failed
+	Repository string
+	Branch     string // 分支名称，用于分支过滤，与分支无关的事件为空
+	Sender     string
+	URL        string
+	Title      string
+	Lines      []string // 标题之后的正文行
+}
+
+// validate 检查代码托管平台 webhook 配置
+func (fc *ForgeConfig) validate(source string) error {
+	if fc == nil {
+		return nil
+	}
+	if source != "" && !forgeSources[source] {
+		return fmt.Errorf("forge 配置仅支持 github、gitea、gitlab 来源，当前来源: %s", source)
+	}
+	for _, event := range fc.Events {
+		if strings.TrimSpace(event) == "" {
+			return fmt.Errorf("forge 事件过滤不能包含空值")
+		}
+	}
+	for _, pattern := range fc.Branches {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("forge 分支过滤格式错误: %q", pattern)
+		}
+	}
+	return nil
+}
+
+// verifyForgeSignature 校验代码托管平台 webhook 签名，返回 0 表示通过，否则返回应响应的HTTP状态码和原因
+// 配置了 forge 的推送配置只接受代码托管平台来源，避免通过其他来源绕过签名校验
+func verifyForgeSignature(r *http.Request, source string, config *ForgeConfig) (int, string) {
+	if !forgeSources[source] {
+		return http.StatusForbidden, fmt.Sprintf("不支持的代码托管平台来源: %q", source)
+	}
+	if config.Secret == "" {
+		return 0, ""
+	}
+
+	// GitLab 直接在请求头中携带密钥
+	if source == "gitlab" {
+		token := r.Header.Get("X-Gitlab-Token")
+		if token == "" {
+			return http.StatusUnauthorized, "缺少 X-Gitlab-Token"
+		}
+		if !hmac.Equal([]byte(token), []byte(config.Secret)) {
+			return http.StatusForbidden, "X-Gitlab-Token 错误"
+		}
+		return 0, ""
+	}
+
+	// GitHub 和 Gitea 使用请求体的 HMAC-SHA256 签名（十六进制），GitHub 带有 sha256= 前缀
+	header, prefix := "X-Hub-Signature-256", "sha256="
+	if source == "gitea" {
+		header, prefix = "X-Gitea-Signature", ""
+	}
+	sign := r.Header.Get(header)
+	if sign == "" {
+		return http.StatusUnauthorized, "缺少 " + header
+	}
+	if !strings.HasPrefix(sign, prefix) {
+		return http.StatusForbidden, header + " 格式错误"
+	}
+
+	body, err := readBody(r)
+	if errors.Is(err, errBodyTooLarge) {
+		return http.StatusRequestEntityTooLarge, err.Error()
+	}
+	if err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+
+	mac := hmac.New(sha256.New, []byte(config.Secret))
+	mac.Write(body)
+	expected := prefix + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.ToLower(sign)), []byte(expected)) {
+		return http.StatusForbidden, header + " 签名错误"
+	}
+	return 0, ""
+}
+
+// forgeMessages 按配置的事件和分支过滤事件并转换为消息，event 为空或被过滤时返回空列表
+func forgeMessages(req *webhookRequest, source string, event *forgeEvent) []webhookMessage {
+	if event == nil || !req.Config.Forge.allows(event) {
+		return nil
+	}
+
+	lines := append([]string{event.Title}, event.Lines...)
+	if event.URL != "" {
+		lines = append(lines, event.URL)
+	}
+	return []webhookMessage{{Params: map[string]string{
+		"title":      event.Title,
+		"msg":        truncateBytes(strings.Join(lines, "\n"), req.MaxLength),
+		"forge":      source,
+		"event":      event.Event,
+		"action":     event.Action,
+		"repository": event.Repository,
+		"branch":     event.Branch,
+		"sender":     event.Sender,
+		"url":        event.URL,
+	}}}
+}
+
+// allows 判断事件是否满足事件和分支过滤条件
+// 事件过滤可以只写事件名（push）或同时指定动作（pull_request.merged）；分支过滤只作用于带分支的事件
+func (fc *ForgeConfig) allows(event *forgeEvent) bool {
+	if fc == nil {
+		return true
+	}
+
+	if len(fc.Events) > 0 {
+		matched := false
+		for _, name := range fc.Events {
+			if name == event.Event || (event.Action != "" && name == event.Event+"."+event.Action) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(fc.Branches) > 0 && event.Branch != "" {
+		for _, pattern := range fc.Branches {
+			if matched, _ := path.Match(pattern, event.Branch); matched {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// forgeCommitLines 格式化提交列表，每个提交显示短哈希、首行提交信息和作者
+func forgeCommitLines(commits []forgeCommit, total int) []string {
+	lines := make([]string, 0, maxForgeCommits+1)
+	for i, commit := range commits {
+		if i == maxForgeCommits {
+			break
+		}
+		message, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		line := fmt.Sprintf("- %s %s", shortSHA(commit.ID), truncateBytes(message, 200))
+		if commit.Author.Name != "" {
+			line += " (" + commit.Author.Name + ")"
+		}
+		lines = append(lines, line)
+	}
+	if total > len(lines) {
+		lines = append(lines, fmt.Sprintf("…另有 %d 个提交", total-len(lines)))
+	}
+	return lines
+}
+
+// forgeCommit 提交信息，GitHub、Gitea、GitLab 格式相同
+type forgeCommit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+	Author  struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+// shortSHA 返回提交哈希的前 7 位
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// isZeroSHA 判断是否为全零提交哈希（表示分支或标签被创建或删除）
+func isZeroSHA(sha string) bool {
+	return sha != "" && strings.Trim(sha, "0") == ""
+}
+
+// pipelineResult 统一流水线结果名称
+func pipelineResult(conclusion string) string {
+	switch conclusion {
+	case "failure", "failed":
+		return "failed"
+	case "cancelled", "canceled":
+		return "canceled"
+	case "timed_out":
+		return "timed_out"
+	default:
+		return conclusion
+	}
+}
+
+// pipelineResultText 流水线结果的显示文本
+func pipelineResultText(result string) string {
+	switch result {
+	case "success":
+		return "✅ 流水线成功"
+	case "failed":
+		return "❌ 流水线失败"
+	case "canceled":
+		return "⚪ 流水线已取消"
+	case "timed_out":
+		return "⏱️ 流水线超时"
+	default:
+		return "流水线" + result
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// githubPayload GitHub webhook 请求结构，Gitea 的请求格式与其兼容
+type githubPayload struct {
+	Action string `json:"action"`
+	Number int    `json:"number"`
+
+	// push
+	Ref        string        `json:"ref"`
+	Before     string        `json:"before"`
+	After      string        `json:"after"`
+	Created    bool          `json:"created"`
+	Deleted    bool          `json:"deleted"`
+	Forced     bool          `json:"forced"`
+	Compare    string        `json:"compare"`     // GitHub
+	CompareURL string        `json:"compare_url"` // Gitea
+	Commits    []forgeCommit `json:"commits"`
+
+	PullRequest *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Merged  bool   `json:"merged"`
+		Head    struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+
+	Issue *struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	} `json:"issue"`
+
+	Release *struct {
+		TagName    string `json:"tag_name"`
+		Name       string `json:"name"`
+		HTMLURL    string `json:"html_url"`
+		Prerelease bool   `json:"prerelease"`
+	} `json:"release"`
+
+	WorkflowRun *struct {
+		Name         string `json:"name"`
+		DisplayTitle string `json:"display_title"`
+		RunNumber    int    `json:"run_number"`
+		HeadBranch   string `json:"head_branch"`
+		HeadSHA      string `json:"head_sha"`
+		Event        string `json:"event"`
+		Conclusion   string `json:"conclusion"`
+		HTMLURL      string `json:"html_url"`
+	} `json:"workflow_run"`
+
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+func init() {
+	RegisterWebhookAdapter("github", func(req *webhookRequest) ([]webhookMessage, error) {
+		return parseGithubWebhook(req, "github", req.Request.Header.Get("X-GitHub-Event"))
+	})
+	RegisterWebhookAdapter("gitea", func(req *webhookRequest) ([]webhookMessage, error) {
+		return parseGithubWebhook(req, "gitea", req.Request.Header.Get("X-Gitea-Event"))
+	})
+}
+
+// parseGithubWebhook 将 GitHub 或 Gitea 的 push、pull_request、issues、release、workflow_run 事件转换为消息，其余事件忽略
+func parseGithubWebhook(req *webhookRequest, source, eventName string) ([]webhookMessage, error) {
+	if eventName == "" {
+		return nil, fmt.Errorf("缺少事件类型请求头")
+	}
+
+	var payload githubPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, err
+	}
+
+	var event *forgeEvent
+	switch eventName {
+	case "push":
+		event = githubPushEvent(&payload)
+	case "pull_request":
+		event = githubPullRequestEvent(&payload)
+	case "issues":
+		event = githubIssueEvent(&payload)
+	case "release":
+		event = githubReleaseEvent(&payload)
+	case "workflow_run":
+		event = githubWorkflowRunEvent(&payload)
+	}
+	if event != nil {
+		event.Repository = payload.Repository.FullName
+		event.Sender = payload.Sender.Login
+	}
+	return forgeMessages(req, source, event), nil
+}
+
+// githubPushEvent 转换分支和标签的推送事件
+func githubPushEvent(payload *githubPayload) *forgeEvent {
+	repo, sender := payload.Repository.FullName, payload.Sender.Login
+	deleted := payload.Deleted || isZeroSHA(payload.After)
+	created := payload.Created || isZeroSHA(payload.Before)
+	compare := payload.Compare
+	if compare == "" {
+		compare = payload.CompareURL
+	}
+
+	if tag, ok := strings.CutPrefix(payload.Ref, "refs/tags/"); ok {
+		event := &forgeEvent{Event: "tag", Action: "created", URL: payload.Repository.HTMLURL + "/releases/tag/" + tag}
+		event.Title = fmt.Sprintf("[%s] %s 创建了标签 %s", repo, sender, tag)
+		if deleted {
+			event.Action, event.URL = "deleted", ""
+			event.Title = fmt.Sprintf("[%s] %s 删除了标签 %s", repo, sender, tag)
+		}
+		return event
+	}
+
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if !ok {
+		return nil
+	}
+	event := &forgeEvent{Event: "push", Action: "pushed", Branch: branch, URL: compare}
+	switch {
+	case deleted:
+		event.Action, event.URL = "deleted", ""
+		event.Title = fmt.Sprintf("[%s] %s 删除了分支 %s", repo, sender, branch)
+	case created && len(payload.Commits) == 0:
+		event.Action = "created"
+		event.Title = fmt.Sprintf("[%s] %s 创建了分支 %s", repo, sender, branch)
+	default:
+		verb := "推送了"
+		if payload.Forced {
+			verb = "强制推送了"
+		}
+		event.Title = fmt.Sprintf("[%s] %s %s %d 个提交到 %s", repo, sender, verb, len(payload.Commits), branch)
+		event.Lines = forgeCommitLines(payload.Commits, len(payload.Commits))
+	}
+	return event
+}
+
+// githubPullRequestEvent 转换拉取请求的创建、关闭、合并和重新打开事件
+func githubPullRequestEvent(payload *githubPayload) *forgeEvent {
+	pr := payload.PullRequest
+	if pr == nil {
+		return nil
+	}
+
+	action := payload.Action
+	if action == "closed" && pr.Merged {
+		action = "merged"
+	}
+	verbs := map[string]string{
+		"opened":   "创建了",
+		"reopened": "重新打开了",
+		"closed":   "关闭了",
+		"merged":   "合并了",
+	}
+	verb, ok := verbs[action]
+	if !ok {
+		return nil
+	}
+
+	number := pr.Number
+	if number == 0 {
+		number = payload.Number
+	}
+	return &forgeEvent{
+		Event:  "pull_request",
+		Action: action,
+		Branch: pr.Base.Ref,
+		URL:    pr.HTMLURL,
+		Title:  fmt.Sprintf("[%s] %s %s PR #%d: %s", payload.Repository.FullName, payload.Sender.Login, verb, number, pr.Title),
+		Lines:  []string{fmt.Sprintf("分支: %s → %s", pr.Head.Ref, pr.Base.Ref)},
+	}
+}
+
+// githubIssueEvent 转换议题的创建、关闭和重新打开事件
+func githubIssueEvent(payload *githubPayload) *forgeEvent {
+	issue := payload.Issue
+	if issue == nil {
+		return nil
+	}
+
+	verbs := map[string]string{
+		"opened":   "创建了",
+		"reopened": "重新打开了",
+		"closed":   "关闭了",
+	}
+	verb, ok := verbs[payload.Action]
+	if !ok {
+		return nil
+	}
+	return &forgeEvent{
+		Event:  "issues",
+		Action: payload.Action,
+		URL:    issue.HTMLURL,
+		Title:  fmt.Sprintf("[%s] %s %s议题 #%d: %s", payload.Repository.FullName, payload.Sender.Login, verb, issue.Number, issue.Title),
+	}
+}
+
+// githubReleaseEvent 转换版本发布事件
+func githubReleaseEvent(payload *githubPayload) *forgeEvent {
+	release := payload.Release
+	if release == nil || payload.Action != "published" {
+		return nil
+	}
+
+	name := release.TagName
+	if release.Name != "" && release.Name != release.TagName {
+		name = fmt.Sprintf("%s (%s)", release.Name, release.TagName)
+	}
+	if release.Prerelease {
+		name += " [预发布]"
+	}
+	return &forgeEvent{
+		Event:  "release",
+		Action: "published",
+		URL:    release.HTMLURL,
+		Title:  fmt.Sprintf("[%s] %s 发布了版本 %s", payload.Repository.FullName, payload.Sender.Login, name),
+	}
+}
+
+// githubWorkflowRunEvent 转换工作流运行完成事件
+func githubWorkflowRunEvent(payload *githubPayload) *forgeEvent {
+	run := payload.WorkflowRun
+	if run == nil || payload.Action != "completed" || run.Conclusion == "" {
+		return nil
+	}
+
+	result := pipelineResult(run.Conclusion)
+	lines := []string{fmt.Sprintf("工作流: %s #%d", run.Name, run.RunNumber)}
+	if run.DisplayTitle != "" {
+		lines = append(lines, "标题: "+run.DisplayTitle)
+	}
+	lines = append(lines, fmt.Sprintf("分支: %s (%s)", run.HeadBranch, shortSHA(run.HeadSHA)))
+	if run.Event != "" {
+		lines = append(lines, "触发: "+run.Event)
+	}
+	return &forgeEvent{
+		Event:  "pipeline",
+		Action: result,
+		Branch: run.HeadBranch,
+		URL:    run.HTMLURL,
+		Title:  fmt.Sprintf("[%s] %s: %s", payload.Repository.FullName, pipelineResultText(result), run.Name),
+		Lines:  lines,
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// gitlabPayload GitLab webhook 请求结构，事件类型由 object_kind 区分
+type gitlabPayload struct {
+	ObjectKind string `json:"object_kind"`
+
+	// push、tag_push
+	Ref               string        `json:"ref"`
+	Before            string        `json:"before"`
+	After             string        `json:"after"`
+	UserName          string        `json:"user_name"`
+	UserUsername      string        `json:"user_username"`
+	Commits           []forgeCommit `json:"commits"`
+	TotalCommitsCount int           `json:"total_commits_count"`
+
+	// merge_request、issue、pipeline、release
+	User *struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectAttributes struct {
+		ID           int    `json:"id"`
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		URL          string `json:"url"`
+		Action       string `json:"action"`
+		SourceBranch string `json:"source_branch"`
+		TargetBranch string `json:"target_branch"`
+
+		// pipeline
+		Ref      string `json:"ref"`
+		Tag      bool   `json:"tag"`
+		SHA      string `json:"sha"`
+		Status   string `json:"status"`
+		Source   string `json:"source"`
+		Duration int    `json:"duration"`
+	} `json:"object_attributes"`
+
+	// release
+	Action string `json:"action"`
+	Tag    string `json:"tag"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+
+	// pipeline
+	Commit *struct {
+		Message string `json:"message"`
+	} `json:"commit"`
+
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		WebURL            string `json:"web_url"`
+	} `json:"project"`
+}
+
+// gitlabActions GitLab 动作名称对应的统一动作名称
+var gitlabActions = map[string]string{
+	"open":   "opened",
+	"reopen": "reopened",
+	"close":  "closed",
+	"merge":  "merged",
+}
+
+func init() {
+	RegisterWebhookAdapter("gitlab", parseGitlabWebhook)
+}
+
+// parseGitlabWebhook 将 GitLab 的 push、tag_push、merge_request、issue、release、pipeline 事件转换为消息，其余事件忽略
+func parseGitlabWebhook(req *webhookRequest) ([]webhookMessage, error) {
+	var payload gitlabPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, err
+	}
+
+	var event *forgeEvent
+	switch payload.ObjectKind {
+	case "push", "tag_push":
+		event = gitlabPushEvent(&payload)
+	case "merge_request":
+		event = gitlabMergeRequestEvent(&payload)
+	case "issue":
+		event = gitlabIssueEvent(&payload)
+	case "release":
+		event = gitlabReleaseEvent(&payload)
+	case "pipeline":
+		event = gitlabPipelineEvent(&payload)
+	}
+	if event != nil {
+		event.Repository = payload.Project.PathWithNamespace
+		event.Sender = payload.sender()
+	}
+	return forgeMessages(req, "gitlab", event), nil
+}
+
+// sender 返回触发事件的用户名
+func (p *gitlabPayload) sender() string {
+	if p.UserUsername != "" {
+		return p.UserUsername
+	}
+	if p.User != nil {
+		return p.User.Username
+	}
+	return p.UserName
+}
+
+// gitlabPushEvent 转换分支和标签的推送事件
+func gitlabPushEvent(payload *gitlabPayload) *forgeEvent {
+	repo, sender := payload.Project.PathWithNamespace, payload.sender()
+	deleted := isZeroSHA(payload.After)
+
+	if tag, ok := strings.CutPrefix(payload.Ref, "refs/tags/"); ok {
+		event := &forgeEvent{Event: "tag", Action: "created", URL: payload.Project.WebURL + "/-/tags/" + tag}
+		event.Title = fmt.Sprintf("[%s] %s 创建了标签 %s", repo, sender, tag)
+		if deleted {
+			event.Action, event.URL = "deleted", ""
+			event.Title = fmt.Sprintf("[%s] %s 删除了标签 %s", repo, sender, tag)
+		}
+		return event
+	}
+
+	branch, ok := strings.CutPrefix(payload.Ref, "refs/heads/")
+	if !ok {
+		return nil
+	}
+	event := &forgeEvent{Event: "push", Action: "pushed", Branch: branch}
+	switch {
+	case deleted:
+		event.Action = "deleted"
+		event.Title = fmt.Sprintf("[%s] %s 删除了分支 %s", repo, sender, branch)
+	case isZeroSHA(payload.Before) && payload.TotalCommitsCount == 0:
+		event.Action = "created"
+		event.URL = payload.Project.WebURL + "/-/tree/" + branch
+		event.Title = fmt.Sprintf("[%s] %s 创建了分支 %s", repo, sender, branch)
+	default:
+		total := payload.TotalCommitsCount
+		if total < len(payload.Commits) {
+			total = len(payload.Commits)
+		}
+		if !isZeroSHA(payload.Before) {
+			event.URL = fmt.Sprintf("%s/-/compare/%s...%s", payload.Project.WebURL, payload.Before, payload.After)
+		}
+		event.Title = fmt.Sprintf("[%s] %s 推送了 %d 个提交到 %s", repo, sender, total, branch)
+		event.Lines = forgeCommitLines(payload.Commits, total)
+	}
+	return event
+}
+
+// gitlabMergeRequestEvent 转换合并请求的创建、关闭、合并和重新打开事件
+func gitlabMergeRequestEvent(payload *gitlabPayload) *forgeEvent {
+	attrs := payload.ObjectAttributes
+	action, ok := gitlabActions[attrs.Action]
+	if !ok {
+		return nil
+	}
+
+	verbs := map[string]string{
+		"opened":   "创建了",
+		"reopened": "重新打开了",
+		"closed":   "关闭了",
+		"merged":   "合并了",
+	}
+	return &forgeEvent{
+		Event:  "pull_request",
+		Action: action,
+		Branch: attrs.TargetBranch,
+		URL:    attrs.URL,
+		Title:  fmt.Sprintf("[%s] %s %s合并请求 !%d: %s", payload.Project.PathWithNamespace, payload.sender(), verbs[action], attrs.IID, attrs.Title),
+		Lines:  []string{fmt.Sprintf("分支: %s → %s", attrs.SourceBranch, attrs.TargetBranch)},
+	}
+}
+
+// gitlabIssueEvent 转换议题的创建、关闭和重新打开事件
+func gitlabIssueEvent(payload *gitlabPayload) *forgeEvent {
+	attrs := payload.ObjectAttributes
+	action, ok := gitlabActions[attrs.Action]
+	if !ok || action == "merged" {
+		return nil
+	}
+
+	verbs := map[string]string{
+		"opened":   "创建了",
+		"reopened": "重新打开了",
+		"closed":   "关闭了",
+	}
+	return &forgeEvent{
+		Event:  "issues",
+		Action: action,
+		URL:    attrs.URL,
+		Title:  fmt.Sprintf("[%s] %s %s议题 #%d: %s", payload.Project.PathWithNamespace, payload.sender(), verbs[action], attrs.IID, attrs.Title),
+	}
+}
+
+// gitlabReleaseEvent 转换版本发布事件
+func gitlabReleaseEvent(payload *gitlabPayload) *forgeEvent {
+	if payload.Action != "create" {
+		return nil
+	}
+
+	name := payload.Tag
+	if payload.Name != "" && payload.Name != payload.Tag {
+		name = fmt.Sprintf("%s (%s)", payload.Name, payload.Tag)
+	}
+	return &forgeEvent{
+		Event:  "release",
+		Action: "published",
+		URL:    payload.URL,
+		Title:  fmt.Sprintf("[%s] 发布了版本 %s", payload.Project.PathWithNamespace, name),
+	}
+}
+
+// gitlabPipelineEvent 转换流水线结束事件，运行中等中间状态忽略
+func gitlabPipelineEvent(payload *gitlabPayload) *forgeEvent {
+	attrs := payload.ObjectAttributes
+	result := pipelineResult(attrs.Status)
+	if result != "success" && result != "failed" && result != "canceled" {
+		return nil
+	}
+
+	lines := []string{fmt.Sprintf("流水线: #%d", attrs.ID)}
+	if payload.Commit != nil {
+		message, _, _ := strings.Cut(strings.TrimSpace(payload.Commit.Message), "\n")
+		lines = append(lines, "提交: "+truncateBytes(message, 200))
+	}
+	branch := attrs.Ref
+	if attrs.Tag {
+		lines = append(lines, fmt.Sprintf("标签: %s (%s)", attrs.Ref, shortSHA(attrs.SHA)))
+		branch = ""
+	} else {
+		lines = append(lines, fmt.Sprintf("分支: %s (%s)", attrs.Ref, shortSHA(attrs.SHA)))
+	}
+	if attrs.Source != "" {
+		lines = append(lines, "触发: "+attrs.Source)
+	}
+	if attrs.Duration > 0 {
+		lines = append(lines, fmt.Sprintf("耗时: %d 秒", attrs.Duration))
+	}
+
+	url := attrs.URL
+	if url == "" && payload.Project.WebURL != "" {
+		url = fmt.Sprintf("%s/-/pipelines/%d", payload.Project.WebURL, attrs.ID)
+	}
+	return &forgeEvent{
+		Event:  "pipeline",
+		Action: result,
+		Branch: branch,
+		URL:    url,
+		Title:  fmt.Sprintf("[%s] %s: %s", payload.Project.PathWithNamespace, pipelineResultText(result), attrs.Ref),
+		Lines:  lines,
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// grafanaPayload Grafana 统一告警 webhook 请求结构，在 Alertmanager 格式基础上增加了标题和面板链接等字段
+type grafanaPayload struct {
+	Receiver          string            `json:"receiver"`
+	Status            string            `json:"status"`
+	OrgID             int               `json:"orgId"`
+	Alerts            []grafanaAlert    `json:"alerts"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	TruncatedAlerts   int               `json:"truncatedAlerts"`
+	Title             string            `json:"title"`
+	State             string            `json:"state"`
+	Message           string            `json:"message"`
+}
+
+// grafanaAlert Grafana 单条告警
+type grafanaAlert struct {
+	alertmanagerAlert
+	DashboardURL string `json:"dashboardURL"`
+	PanelURL     string `json:"panelURL"`
+	SilenceURL   string `json:"silenceURL"`
+	ValueString  string `json:"valueString"`
+	ImageURL     string `json:"imageURL"`
+}
+
+func init() {
+	RegisterWebhookAdapter("grafana", parseGrafanaWebhook)
+}
+
+// parseGrafanaWebhook 将 Grafana 告警按 firing/resolved 状态各转换为一条消息，附带告警值和仪表盘、面板链接
+func parseGrafanaWebhook(req *webhookRequest) ([]webhookMessage, error) {
+	var payload grafanaPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, err
+	}
+
+	var messages []webhookMessage
+	for _, status := range []string{"firing", "resolved"} {
+		var alerts []grafanaAlert
+		for _, alert := range payload.Alerts {
+			if alert.Status == status {
+				alerts = append(alerts, alert)
+			}
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+
+		name := payload.CommonLabels["alertname"]
+		if name == "" {
+			name = formatLabels(payload.GroupLabels, nil)
+		}
+		title := alertTitle(status, len(alerts), name)
+
+		header := title + "\n"
+		if labels := formatLabels(payload.CommonLabels, nil); labels != "" {
+			header += "公共标签: " + labels + "\n"
+		}
+		header += "\n"
+
+		blocks := make([]string, len(alerts))
+		dashboardURL, panelURL := "", ""
+		for i, alert := range alerts {
+			lines := []string{formatAlertmanagerAlert(i+1, alert.alertmanagerAlert, payload.CommonLabels)}
+			if alert.ValueString != "" {
+				lines = append(lines, "告警值: "+alert.ValueString)
+			}
+			if alert.DashboardURL != "" {
+				lines = append(lines, "仪表盘: "+alert.DashboardURL)
+			}
+			if alert.PanelURL != "" {
+				lines = append(lines, "面板: "+alert.PanelURL)
+			}
+			blocks[i] = strings.Join(lines, "\n")
+
+			if dashboardURL == "" {
+				dashboardURL = alert.DashboardURL
+			}
+			if panelURL == "" {
+				panelURL = alert.PanelURL
+			}
+		}
+
+		messages = append(messages, splitAlertMessages(title, header, blocks, req.MaxLength, map[string]string{
+			"status":        status,
+			"alertname":     payload.CommonLabels["alertname"],
+			"severity":      payload.CommonLabels["severity"],
+			"receiver":      payload.Receiver,
+			"external_url":  payload.ExternalURL,
+			"dashboard_url": dashboardURL,
+			"panel_url":     panelURL,
+			"alert_count":   strconv.Itoa(len(alerts)),
+		})...)
+	}
+	return messages, nil
+}
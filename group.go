@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// GroupConfig 群发配置，将一条消息同时发送到多个推送配置
+type GroupConfig struct {
+	Targets []string
+	Policy  string // all: 全部成功才算成功; any: 任意一个成功即算成功
+}
+
+// groupResult 单个目标的发送结果
+type groupResult struct {
+	Target string
+	Result string
+	Err    error
+}
+
+// groupError 群发未满足成功策略，失败的目标已按各自的重试配置加入重试队列，调用方不应再重试整个群发
+type groupError struct {
+	message string
+	Queued  bool // 所有失败的目标都已加入重试队列
+}
+
+func (e *groupError) Error() string {
+	return e.message
+}
+
+// asGroupError 判断错误是否来自群发，群发的失败目标由群发自身逐个重试
+func asGroupError(err error) (*groupError, bool) {
+	var groupErr *groupError
+	ok := errors.As(err, &groupErr)
+	return groupErr, ok
+}
+
+// groupTarget 群发中正在发送的目标，Params 为模板渲染前的参数
+type groupTarget struct {
+	Group  string
+	Target string
+	Params map[string]string
+}
+
+// groupTargetKey 上下文中正在发送的群发目标
+type groupTargetKey struct{}
+
+// groupTargetFrom 返回上下文中正在发送的群发目标，只匹配该目标自身的配置
+// 故障转移中的群发不单独重试失败的目标，此时返回 nil
+func groupTargetFrom(ctx context.Context, configName string) *groupTarget {
+	target, _ := ctx.Value(groupTargetKey{}).(*groupTarget)
+	if target == nil || target.Target != configName || ctx.Value(groupRetryKey{}) != nil {
+		return nil
+	}
+	return target
+}
+
+// groupRetryKey 上下文中禁用群发逐个目标重试的标记
+type groupRetryKey struct{}
+
+// withoutGroupRetry 返回不将群发失败目标加入重试队列的上下文，供故障转移等自行处理失败的调用方使用
+func withoutGroupRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, groupRetryKey{}, true)
+}
+
+// groupChannel 群发渠道，额外声明引用的目标配置供配置校验使用
+type groupChannel struct {
+	Channel
+}
+
+// References 返回群发引用的目标配置名称
+func (groupChannel) References(configData map[string]interface{}) []string {
+	config, err := convertToGroupConfig(configData)
+	if err != nil {
+		return nil
+	}
+	return config.Targets
+}
+
+func init() {
+	RegisterChannel(groupChannel{NewChannel("group",
+		[]ConfigField{
+			{Name: "Targets", Required: true, Description: "目标配置名称列表"},
+			{Name: "Policy", Description: "成功策略: all(默认) 或 any"},
+		},
+		func(configData map[string]interface{}) error {
+			_, err := convertToGroupConfig(configData)
+			return err
+		},
+		SendGroup,
+	)})
+}
+
+// SendGroup 并发发送到群发配置中的所有目标 - 统一接口
+func SendGroup(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	config, err := convertToGroupConfig(configData)
+	if err != nil {
+		return "", err
+	}
+
+	results := make([]groupResult, len(config.Targets))
+	var wg sync.WaitGroup
+	for i, target := range config.Targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+			targetCtx := context.WithValue(ctx, groupTargetKey{}, &groupTarget{Group: configName, Target: target, Params: params})
+			result, err := sendToTarget(targetCtx, target, params)
+			results[i] = groupResult{Target: target, Result: result, Err: err}
+		}(i, target)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, r := range results {
+		if r.Err == nil {
+			succeeded++
+		}
+	}
+	satisfied := (config.Policy == "any" && succeeded > 0) || succeeded == len(results)
+
+	// 汇总每个目标的结果，未满足策略时失败的目标逐个加入重试队列，避免重发已成功的目标
+	queued := 0
+	lines := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Err == nil {
+			lines = append(lines, fmt.Sprintf("%s: %s", r.Target, r.Result))
+			continue
+		}
+		if !satisfied && ctx.Value(groupRetryKey{}) == nil {
+			jobID, ok := enqueueGroupTarget(configName, r.Target, params, r.Err)
+			if ok {
+				queued++
+			}
+			if jobID != "" {
+				lines = append(lines, fmt.Sprintf("%s: Queued: %s (Error: %v)", r.Target, jobID, r.Err))
+				continue
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s: Error: %v", r.Target, r.Err))
+	}
+	summary := strings.Join(lines, "\n")
+	fmt.Printf("[%s] %s - 群发结果 %d/%d 成功\n", timestamp(), configName, succeeded, len(results))
+
+	if satisfied {
+		return summary, nil
+	}
+	return "", &groupError{
+		message: fmt.Sprintf("群发未满足 %s 策略 (%d/%d 成功)\n%s", config.Policy, succeeded, len(results), summary),
+		Queued:  queued == len(results)-succeeded,
+	}
+}
+
+// enqueueGroupTarget 将发送失败的目标加入重试队列，优先使用目标自身的重试配置，未配置时使用群发的重试配置
+// 目标本身是群发时，其失败的目标已由内层群发处理；请求参数错误时重试也无法成功，不加入重试队列
+func enqueueGroupTarget(groupName, target string, params map[string]string, sendErr error) (string, bool) {
+	if groupErr, ok := asGroupError(sendErr); ok {
+		return "", groupErr.Queued
+	}
+	if isInvalidParam(sendErr) {
+		return "", false
+	}
+
+	cm := getConfigManager()
+	retry := cm.Configs[target].Retry
+	if retry == nil {
+		retry = cm.Configs[groupName].Retry
+	}
+	if retry == nil {
+		return "", false
+	}
+
+	jobID, err := retryQueue.EnqueueTarget(groupName, target, retry, params, sendErr)
+	if err != nil {
+		fmt.Printf("[%s] %s - 目标 %s 加入重试队列失败: %v\n", timestamp(), groupName, target, err)
+		return "", false
+	}
+	return jobID, true
+}
+
+// convertToGroupConfig 将通用配置转换为群发配置
+func convertToGroupConfig(config map[string]interface{}) (GroupConfig, error) {
+	targetsInterface, ok := config["Targets"].([]interface{})
+	if !ok || len(targetsInterface) == 0 {
+		return GroupConfig{}, fmt.Errorf("缺少 Targets 配置")
+	}
+
+	targets := make([]string, len(targetsInterface))
+	for i, targetInterface := range targetsInterface {
+		target, ok := targetInterface.(string)
+		if !ok || target == "" {
+			return GroupConfig{}, fmt.Errorf("配置 Targets 格式错误")
+		}
+		targets[i] = target
+	}
+
+	policy, _ := config["Policy"].(string)
+	if policy == "" {
+		policy = "all"
+	}
+	if policy != "all" && policy != "any" {
+		return GroupConfig{}, fmt.Errorf("不支持的群发策略: %s", policy)
+	}
+
+	return GroupConfig{
+		Targets: targets,
+		Policy:  policy,
+	}, nil
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -10,6 +11,7 @@ import (
 type HeartbeatService struct {
 	URL      string
 	Interval int
+	stop     chan struct{}
 }
 
 // Start 启动心跳检测服务
@@ -20,19 +22,33 @@ func (h *HeartbeatService) Start() {
 	}
 
 	// 在独立的goroutine中运行心跳检测
-	go func() {
+	h.stop = make(chan struct{})
+	go func(stop chan struct{}) {
 		ticker := time.NewTicker(time.Duration(h.Interval) * time.Second)
 		defer ticker.Stop()
 
-		for range ticker.C {
-			h.sendHeartbeat()
+		for {
+			select {
+			case <-ticker.C:
+				h.sendHeartbeat()
+			case <-stop:
+				return
+			}
 		}
-	}()
+	}(h.stop)
+}
+
+// Stop 停止心跳检测服务
+func (h *HeartbeatService) Stop() {
+	if h.stop != nil {
+		close(h.stop)
+		h.stop = nil
+	}
 }
 
 // sendHeartbeat 发送心跳请求
 func (h *HeartbeatService) sendHeartbeat() {
-	response, err := httpRequest("GET", h.URL, nil, 30*time.Second)
+	response, err := httpRequest(context.Background(), "GET", h.URL, nil, 30*time.Second)
 	if err != nil {
 		fmt.Printf("[%s] 心跳检测失败: %v\n", timestamp(), err)
 		return
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// IPFilterConfig IP访问控制配置，支持单个IP或CIDR网段
+type IPFilterConfig struct {
+	Allow []string `json:"allow"` // 允许访问的地址，为空时不限制
+	Deny  []string `json:"deny"`  // 拒绝访问的地址，优先于 allow
+}
+
+// validate 检查地址格式是否正确
+func (f *IPFilterConfig) validate() error {
+	if f == nil {
+		return nil
+	}
+	if _, err := parsePrefixes(f.Allow); err != nil {
+		return err
+	}
+	if _, err := parsePrefixes(f.Deny); err != nil {
+		return err
+	}
+	return nil
+}
+
+// allows 判断地址是否允许访问
+func (f *IPFilterConfig) allows(addr netip.Addr) bool {
+	if f == nil {
+		return true
+	}
+
+	deny, _ := parsePrefixes(f.Deny)
+	if containsAddr(deny, addr) {
+		return false
+	}
+
+	allow, _ := parsePrefixes(f.Allow)
+	return len(allow) == 0 || containsAddr(allow, addr)
+}
+
+// parsePrefixes 解析IP或CIDR列表，单个IP视为仅包含该地址的网段
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("无效的网段: %s", value)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("无效的IP地址: %s", value)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// containsAddr 判断地址是否属于任意一个网段
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 获取请求的真实客户端IP
+// 仅当直接连接方是可信代理时才使用 X-Forwarded-For/X-Real-IP，从右向左跳过可信代理取第一个地址
+func (cm *ConfigManager) clientIP(r *http.Request) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("无法解析客户端地址: %s", r.RemoteAddr)
+	}
+	remote = remote.Unmap()
+
+	trusted, _ := parsePrefixes(cm.TrustedProxies)
+	if !containsAddr(trusted, remote) {
+		return remote, nil
+	}
+
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			addr = addr.Unmap()
+			if i == 0 || !containsAddr(trusted, addr) {
+				return addr, nil
+			}
+		}
+	}
+
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(realIP)); err == nil {
+			return addr.Unmap(), nil
+		}
+	}
+
+	return remote, nil
+}
+
+// checkIPFilter 检查全局或配置自身的IP访问控制，返回客户端IP及是否允许访问
+func (cm *ConfigManager) checkIPFilter(r *http.Request, filter *IPFilterConfig) (string, bool) {
+	addr, err := cm.clientIP(r)
+	if err != nil {
+		return r.RemoteAddr, filter == nil
+	}
+	return addr.String(), filter.allows(addr)
+}
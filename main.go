@@ -2,19 +2,42 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
-// 全局配置管理器
-var configManager *ConfigManager
+// 配置文件路径
+const configFile = "data/config.json"
+
+// 全局配置管理器，热重载时整体原子替换，读取方不应修改其内容
+var currentConfig atomic.Pointer[ConfigManager]
+
+// 全局心跳检测服务
+var heartbeat *HeartbeatService
+
+// getConfigManager 获取当前生效的配置管理器
+func getConfigManager() *ConfigManager {
+	return currentConfig.Load()
+}
 
 // dynamicHandler 动态路由处理器
 func dynamicHandler(w http.ResponseWriter, r *http.Request) {
+	// 获取当前配置快照，保证单个请求内配置一致
+	configManager := getConfigManager()
+
 	// 设置响应头
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 
+	// 限制请求体大小
+	r.Body = http.MaxBytesReader(w, r.Body, configManager.maxBodySize())
+
 	// 从URL路径中提取配置名称
 	fullPath := strings.Trim(r.URL.Path, "/")
 
@@ -44,8 +67,61 @@ func dynamicHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取配置
+	// 获取配置，路径末段为 webhook 来源时（例如 /配置名/alertmanager）按该来源解析请求
 	config, exists := configManager.GetConfig(configPath)
+	if !exists {
+		if i := strings.LastIndex(configPath, "/"); i > 0 {
+			if _, ok := GetWebhookAdapter(configPath[i+1:]); ok {
+				if sourceConfig, ok := configManager.GetConfig(configPath[:i]); ok {
+					config, exists = sourceConfig, true
+					config.Source = configPath[i+1:]
+					configPath = configPath[:i]
+				}
+			}
+		}
+	}
+
+	// 定时消息管理路径: /配置名/scheduled 和 /配置名/scheduled/任务ID
+	scheduleRequest, scheduleJobID := false, ""
+	if !exists {
+		if name, jobID, ok := parseSchedulePath(configPath); ok {
+			if scheduleConfig, ok := configManager.GetConfig(name); ok {
+				config, exists = scheduleConfig, true
+				configPath, scheduleRequest, scheduleJobID = name, true, jobID
+			}
+		}
+	}
+	source := config.Source
+	if source == "" && config.Mapping != nil {
+		source = "json"
+	}
+	platform := config.Type
+	if !exists {
+		platform = "unknown"
+	}
+
+	// 检查客户端IP是否允许访问全局
+	if clientIP, allowed := configManager.checkIPFilter(r, configManager.IPFilter); !allowed {
+		rejectClientIP(w, configPath, platform, clientIP)
+		return
+	}
+
+	// 校验访问令牌，在判断配置是否存在之前进行，避免暴露配置是否存在
+	if status := configManager.authorize(r, config, exists); status != 0 {
+		ts := timestamp()
+		errorMsg := fmt.Sprintf("鉴权失败 - %s", http.StatusText(status))
+
+		// 写入错误日志
+		writeErrorLog(ts, configPath, platform, errorMsg, nil)
+
+		if status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		http.Error(w, http.StatusText(status), status)
+		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+		return
+	}
+
 	if !exists {
 		ts := timestamp()
 		errorMsg := fmt.Sprintf("这里是一片荒原 - 配置 '%s' 不存在", configPath)
@@ -58,50 +134,252 @@ func dynamicHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 获取消息内容 - 缺少msg参数
-	msg := r.FormValue("msg")
-	if msg == "" {
+	// 检查客户端IP是否允许访问该配置，在鉴权之后进行，避免暴露配置是否存在
+	if clientIP, allowed := configManager.checkIPFilter(r, config.IPFilter); !allowed {
+		rejectClientIP(w, configPath, platform, clientIP)
+		return
+	}
+
+	// 校验请求签名
+	if config.Signature != nil {
+		if status, reason := verifySignature(r, config.Signature); status != 0 {
+			ts := timestamp()
+			errorMsg := fmt.Sprintf("签名校验失败 - %s", reason)
+
+			// 写入错误日志
+			writeErrorLog(ts, configPath, config.Type, errorMsg, nil)
+
+			http.Error(w, http.StatusText(status), status)
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return
+		}
+	}
+
+	// 校验代码托管平台 webhook 签名
+	if config.Forge != nil {
+		if status, reason := verifyForgeSignature(r, source, config.Forge); status != 0 {
+			ts := timestamp()
+			errorMsg := fmt.Sprintf("签名校验失败 - %s", reason)
+
+			// 写入错误日志
+			writeErrorLog(ts, configPath, config.Type, errorMsg, nil)
+
+			http.Error(w, http.StatusText(status), status)
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return
+		}
+	}
+
+	// 列出或取消定时消息
+	if scheduleRequest {
+		handleScheduleRequest(w, r, configPath, scheduleJobID)
+		return
+	}
+
+	// 获取所有请求参数（排除鉴权和签名参数）
+	var excluded []string
+	if _, required := configManager.authKeys(config, exists); required {
+		excluded = append(excluded, "token")
+	}
+	if config.Signature != nil {
+		excluded = append(excluded, "timestamp", "sign")
+	}
+
+	// 解析请求，webhook 来源的请求由对应的适配器转换为一条或多条消息
+	var messages []webhookMessage
+	var err error
+	if source != "" {
+		messages, err = parseWebhookRequest(r, source, configPath, config, excluded...)
+	} else {
+		var params map[string]string
+		var data map[string]interface{}
+		params, data, err = parseRequest(r, excluded...)
+		messages = []webhookMessage{{Params: params, Data: data}}
+	}
+	if err != nil {
 		ts := timestamp()
-		errorMsg := "Wel Come! - 缺少msg参数"
+		errorMsg := fmt.Sprintf("Error: %v", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, errBodyTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
 
 		// 写入错误日志
 		writeErrorLog(ts, configPath, config.Type, errorMsg, nil)
 
-		http.Error(w, "Wel Come!", http.StatusBadRequest)
-		fmt.Printf("[%s] %s\n", ts, errorMsg)
+		http.Error(w, errorMsg, status)
+		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
 		return
 	}
 
-	// 获取所有表单参数
-	params := make(map[string]string)
-	params["msg"] = msg
-	params["title"] = r.FormValue("title")
+	// webhook 请求中没有需要推送的消息
+	if len(messages) == 0 {
+		fmt.Printf("[%s] %s - 没有需要推送的消息\n", timestamp(), configPath)
+		fmt.Fprint(w, "Ignored")
+		return
+	}
 
-	// 根据配置类型处理消息
-	var result string
-	var err error
+	// 逐条发送消息并汇总结果
+	var response pushResponse
+	bodies := make([]string, 0, len(messages))
+	for _, message := range messages {
+		resp := deliverMessage(configPath, config, message.Params, message.Data)
+		if resp.Status > response.Status {
+			response.Status = resp.Status
+		}
+		if resp.RetryAfter > response.RetryAfter {
+			response.RetryAfter = resp.RetryAfter
+		}
+		bodies = append(bodies, resp.Body)
+	}
+	response.Body = strings.Join(bodies, "\n")
+
+	// 返回响应
+	if response.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(response.RetryAfter))
+	}
+	if response.Status >= http.StatusBadRequest {
+		http.Error(w, response.Body, response.Status)
+		return
+	}
+	w.WriteHeader(response.Status)
+	fmt.Fprint(w, response.Body)
+}
+
+// pushResponse 单条消息的处理结果
+type pushResponse struct {
+	Status     int
+	Body       string
+	RetryAfter int // 限流时建议的重试等待秒数
+}
+
+// rejectClientIP 拒绝不允许访问的客户端IP并记录日志
+func rejectClientIP(w http.ResponseWriter, configPath, platform, clientIP string) {
+	ts := timestamp()
+	errorMsg := fmt.Sprintf("IP访问受限 - 客户端 %s", clientIP)
+
+	// 写入错误日志
+	writeErrorLog(ts, configPath, platform, errorMsg, nil)
+
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+}
 
-	switch config.Type {
-	case "dingtalk_text":
-		result, err = SendDingTalkText(configPath, config.Config, params)
-	case "telegram_text":
-		result, err = SendTelegramText(configPath, config.Config, params)
-	case "wecom_mpnews":
-		result, err = SendWecomMPNews(configPath, config.Config, params)
-	case "wecom_robot_text":
-		result, err = SendWecomRobotText(configPath, config.Config, params)
-	default:
+// deliverMessage 渲染模板并发送单条消息，记录日志并返回响应内容
+func deliverMessage(configPath string, config PushConfig, params map[string]string, data map[string]interface{}) pushResponse {
+	// 指定了发送时间的消息加入定时队列，到时间后再按完整流程发送
+	sendAt, err := parseSendTime(params, data)
+	if err != nil {
+		ts := timestamp()
+		errorMsg := fmt.Sprintf("Error: %v", err)
+
+		// 写入错误日志
+		writeErrorLog(ts, configPath, config.Type, errorMsg, params)
+
+		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+		return pushResponse{Status: http.StatusBadRequest, Body: errorMsg}
+	}
+	if sendAt.After(time.Now()) {
+		jobID, err := scheduleQueue.Add(configPath, sendAt, params, data)
+		if err != nil {
+			ts := timestamp()
+			errorMsg := fmt.Sprintf("Error: 加入定时消息队列失败: %v", err)
+
+			// 写入错误日志
+			writeErrorLog(ts, configPath, config.Type, errorMsg, params)
+
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return pushResponse{Status: http.StatusInternalServerError, Body: errorMsg}
+		}
+		result := fmt.Sprintf("Scheduled: %s (%s)", jobID, sendAt.Local().Format("2006-01-02 15:04:05"))
+		fmt.Printf("[%s] %s - %s\n", timestamp(), configPath, result)
+		return pushResponse{Status: http.StatusAccepted, Body: result}
+	}
+
+	// 保留模板渲染前的参数，免打扰时段结束后重新按完整流程发送
+	rawParams := copyParams(params)
+
+	// 使用配置的模板渲染消息和标题
+	if err := applyTemplates(config, params, data); err != nil {
+		ts := timestamp()
+		errorMsg := fmt.Sprintf("Error: %v", err)
+
+		// 写入错误日志
+		writeErrorLog(ts, configPath, config.Type, errorMsg, params)
+
+		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+		return pushResponse{Status: http.StatusBadRequest, Body: errorMsg}
+	}
+
+	// 获取消息内容 - 缺少msg参数
+	if params["msg"] == "" {
+		ts := timestamp()
+		errorMsg := "Wel Come! - 缺少msg参数"
+
+		// 写入错误日志
+		writeErrorLog(ts, configPath, config.Type, errorMsg, nil)
+
+		fmt.Printf("[%s] %s\n", ts, errorMsg)
+		return pushResponse{Status: http.StatusBadRequest, Body: "Wel Come!"}
+	}
+
+	// 根据配置类型查找推送渠道并发送消息
+	if _, supported := GetChannel(config.Type); !supported {
 		ts := timestamp()
 		errorMsg := fmt.Sprintf("不支持的推送类型: %s", config.Type)
 
 		// 写入错误日志
 		writeErrorLog(ts, configPath, config.Type, errorMsg, params)
 
-		http.Error(w, errorMsg, http.StatusBadRequest)
 		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
-		return
+		return pushResponse{Status: http.StatusBadRequest, Body: errorMsg}
+	}
+
+	// 免打扰时段内的非紧急消息按配置暂存或丢弃
+	if config.QuietHours != nil && params["priority"] != "urgent" {
+		if until, active := config.QuietHours.activeUntil(time.Now()); active {
+			return holdQuietMessage(configPath, config, until, rawParams, params, data)
+		}
 	}
 
+	// 去重窗口内的重复消息不再发送，只累加抑制次数
+	var dedupID string
+	var repeats int
+	if config.Dedup != nil {
+		dedupID = dedupKey(configPath, params)
+		delete(params, "dedup_key")
+
+		var suppressed bool
+		suppressed, repeats = dedup.check(dedupID, time.Duration(config.Dedup.Window)*time.Second)
+		if suppressed {
+			result := fmt.Sprintf("Suppressed: 重复消息已抑制 %d 次", repeats)
+			fmt.Printf("[%s] %s - %s\n", timestamp(), configPath, result)
+			return pushResponse{Status: http.StatusOK, Body: result}
+		}
+		if repeats > 0 && config.Dedup.ShowRepeats {
+			params["msg"] += fmt.Sprintf("\n(重复 %d 次)", repeats)
+		}
+	}
+
+	// 汇总模式下消息先暂存，到达汇总时间或上限时合并发送
+	if config.Digest != nil {
+		count, err := digestQueue.Add(configPath, config.Digest, params)
+		if err != nil {
+			ts := timestamp()
+			errorMsg := fmt.Sprintf("Error: 加入汇总队列失败: %v", err)
+
+			// 写入错误日志
+			writeErrorLog(ts, configPath, config.Type, errorMsg, params)
+
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return pushResponse{Status: http.StatusInternalServerError, Body: errorMsg}
+		}
+		result := fmt.Sprintf("Buffered: 已加入汇总，当前 %d 条", count)
+		fmt.Printf("[%s] %s - %s\n", timestamp(), configPath, result)
+		return pushResponse{Status: http.StatusAccepted, Body: result}
+	}
+
+	result, err := sendToConfig(context.Background(), configPath, params)
 	if err != nil {
 		ts := timestamp()
 		errorMsg := fmt.Sprintf("Error: %v", err)
@@ -109,14 +387,57 @@ func dynamicHandler(w http.ResponseWriter, r *http.Request) {
 		// 写入错误日志
 		writeErrorLog(ts, configPath, config.Type, errorMsg, params)
 
-		http.Error(w, errorMsg, http.StatusInternalServerError)
+		// 触发限流时返回429，由调用方稍后重试
+		var rateErr *rateLimitError
+		if errors.As(err, &rateErr) {
+			if dedupID != "" {
+				dedup.release(dedupID, repeats)
+			}
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return pushResponse{
+				Status:     http.StatusTooManyRequests,
+				Body:       errorMsg,
+				RetryAfter: int(math.Ceil(rateErr.RetryAfter.Seconds())),
+			}
+		}
+
+		// 请求参数错误时重试也无法成功，直接返回400
+		if isInvalidParam(err) {
+			if dedupID != "" {
+				dedup.release(dedupID, repeats)
+			}
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return pushResponse{Status: http.StatusBadRequest, Body: errorMsg}
+		}
+
+		// 群发失败的目标已各自加入重试队列
+		if groupErr, ok := asGroupError(err); ok && groupErr.Queued {
+			fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+			return pushResponse{Status: http.StatusAccepted, Body: "Queued: " + err.Error()}
+		}
+
+		// 配置了重试时加入重试队列，由后台任务继续发送，群发只重试失败的目标
+		if _, isGroup := asGroupError(err); config.Retry != nil && !isGroup {
+			jobID, queueErr := retryQueue.Enqueue(configPath, config.Retry, params, err)
+			if queueErr == nil {
+				result = fmt.Sprintf("Queued: %s", jobID)
+				fmt.Printf("[%s] %s - %s - %s\n", ts, configPath, errorMsg, result)
+				return pushResponse{Status: http.StatusAccepted, Body: result}
+			}
+			fmt.Printf("[%s] %s - 加入重试队列失败: %v\n", ts, configPath, queueErr)
+		}
+
+		// 未发送成功的消息不计入去重窗口，下一条相同消息可以继续发送
+		if dedupID != "" {
+			dedup.release(dedupID, repeats)
+		}
+
 		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
-		return
+		return pushResponse{Status: http.StatusInternalServerError, Body: errorMsg}
 	}
 
-	// 返回响应
 	fmt.Printf("[%s] %s - %s\n", timestamp(), configPath, result)
-	fmt.Fprint(w, result)
+	return pushResponse{Status: http.StatusOK, Body: result}
 }
 
 func main() {
@@ -124,24 +445,54 @@ func main() {
 	logStartupTime()
 
 	// 加载配置文件
-	var err error
-	configManager, err = NewConfigManager("data/config.json")
+	configManager, err := LoadConfig(configFile)
 	if err != nil {
 		fmt.Printf("加载配置文件失败: %v\n", err)
+		if errors.Is(err, errMissingRoute) {
+			fmt.Printf("请在 config.json 中设置全局路由，例如:\n")
+			fmt.Printf("  \"route\": \"/\"          # 无前缀\n")
+			fmt.Printf("  \"route\": \"/push\"      # 有前缀\n")
+		}
 		return
 	}
+	currentConfig.Store(configManager)
 
-	// 检查全局路由配置
-	if configManager.Route == "" {
-		fmt.Printf("错误: 配置文件中缺少 'route' 字段或值为空\n")
-		fmt.Printf("请在 config.json 中设置全局路由，例如:\n")
-		fmt.Printf("  \"route\": \"/\"          # 无前缀\n")
-		fmt.Printf("  \"route\": \"/push\"      # 有前缀\n")
+	// 加载重试队列并启动后台重试任务
+	retryQueue, err = NewRetryQueue("data/retry_queue.json")
+	if err != nil {
+		fmt.Printf("加载重试队列失败: %v\n", err)
 		return
 	}
+	retryQueue.Start()
+	if pending := retryQueue.Len(); pending > 0 {
+		fmt.Printf("重试队列中有 %d 条待发送消息\n", pending)
+	}
+
+	// 加载汇总队列并启动后台汇总任务
+	digestQueue, err = NewDigestQueue("data/digest_queue.json")
+	if err != nil {
+		fmt.Printf("加载汇总队列失败: %v\n", err)
+		return
+	}
+	digestQueue.Start()
+	if pending := digestQueue.Len(); pending > 0 {
+		fmt.Printf("汇总队列中有 %d 条待发送消息\n", pending)
+	}
+
+	// 加载定时消息队列并启动后台定时发送任务
+	scheduleQueue, err = NewScheduleQueue("data/schedule_queue.json")
+	if err != nil {
+		fmt.Printf("加载定时消息队列失败: %v\n", err)
+		return
+	}
+	scheduleQueue.Start()
+	startUploadCleanup()
+	if pending := scheduleQueue.Len(); pending > 0 {
+		fmt.Printf("定时消息队列中有 %d 条待发送消息\n", pending)
+	}
 
 	// 启动心跳检测服务
-	heartbeat := &HeartbeatService{
+	heartbeat = &HeartbeatService{
 		URL:      configManager.HeartbeatURL,
 		Interval: configManager.HeartbeatInterval,
 	}
@@ -154,6 +505,13 @@ func main() {
 		fmt.Println("心跳检测未配置")
 	}
 
+	// 启动配置热重载（SIGHUP 信号及可选的文件变更检测）
+	watchConfigSignal(configFile)
+	if configManager.ConfigWatchInterval > 0 {
+		watchConfigFile(configFile, configManager.ConfigWatchInterval)
+		fmt.Printf("配置文件变更检测已启动 (间隔: %d秒)\n", configManager.ConfigWatchInterval)
+	}
+
 	// 注册动态路由
 	http.HandleFunc("/", dynamicHandler)
 
@@ -166,6 +524,8 @@ func main() {
 		fmt.Printf("全局路由前缀: %s\n", configManager.Route)
 	}
 
+	fmt.Printf("已注册的推送类型: %s\n", strings.Join(GetAllChannelTypes(), ", "))
+
 	fmt.Println("支持的配置路由:")
 	for _, name := range configManager.GetAllConfigNames() {
 		config, _ := configManager.GetConfig(name)
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MappingConfig 通用 JSON webhook 映射配置，通过路径表达式从请求体中提取参数
+type MappingConfig struct {
+	Fields map[string]string  `json:"fields"` // 参数名 -> 路径表达式，例如 "title": "$.alert.name"
+	Drop   []MappingCondition `json:"drop"`   // 丢弃条件，满足任意一条时不推送
+}
+
+// MappingCondition 丢弃条件，同一条件中设置的多个判断需要同时满足
+type MappingCondition struct {
+	Path      string        `json:"path"`       // 路径表达式
+	Equals    interface{}   `json:"equals"`     // 值等于
+	NotEquals interface{}   `json:"not_equals"` // 值不等于
+	In        []interface{} `json:"in"`         // 值为其中之一
+	Exists    *bool         `json:"exists"`     // 字段是否存在
+	Matches   string        `json:"matches"`    // 值匹配正则表达式
+}
+
+func init() {
+	RegisterWebhookAdapter("json", parseMappedWebhook)
+}
+
+// validate 检查映射配置中的路径表达式和丢弃条件
+func (mc *MappingConfig) validate(source string) error {
+	if mc == nil {
+		return nil
+	}
+	if source != "" && source != "json" {
+		return fmt.Errorf("mapping 配置仅支持 json 来源，当前来源: %s", source)
+	}
+	if len(mc.Fields) == 0 {
+		return fmt.Errorf("mapping 缺少 fields")
+	}
+	for name, expr := range mc.Fields {
+		if isReservedParam(name) {
+			return fmt.Errorf("mapping 字段不能映射到内部参数 %s", name)
+		}
+		if _, err := parseJSONPath(expr); err != nil {
+			return fmt.Errorf("mapping 字段 '%s' 无效: %v", name, err)
+		}
+	}
+	for i, cond := range mc.Drop {
+		if _, err := parseJSONPath(cond.Path); err != nil {
+			return fmt.Errorf("mapping 第 %d 个丢弃条件无效: %v", i+1, err)
+		}
+		if cond.Equals == nil && cond.NotEquals == nil && cond.In == nil && cond.Exists == nil && cond.Matches == "" {
+			return fmt.Errorf("mapping 第 %d 个丢弃条件缺少判断", i+1)
+		}
+		if _, err := regexp.Compile(cond.Matches); err != nil {
+			return fmt.Errorf("mapping 第 %d 个丢弃条件的正则表达式错误: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// parseMappedWebhook 按配置的映射从 JSON 请求体中提取参数，满足丢弃条件时返回空列表
+func parseMappedWebhook(req *webhookRequest) ([]webhookMessage, error) {
+	mapping := req.Config.Mapping
+	if mapping == nil {
+		return nil, fmt.Errorf("配置缺少 mapping")
+	}
+
+	var payload interface{}
+	decoder := json.NewDecoder(bytes.NewReader(req.Body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+
+	for _, cond := range mapping.Drop {
+		drop, err := cond.match(payload)
+		if err != nil {
+			return nil, err
+		}
+		if drop {
+			return nil, nil
+		}
+	}
+
+	params := make(map[string]string, len(mapping.Fields))
+	data := make(map[string]interface{}, len(mapping.Fields))
+	for name, expr := range mapping.Fields {
+		value, found, err := lookupJSONPath(payload, expr)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		params[name] = jsonValueString(value)
+		data[name] = value
+	}
+	return []webhookMessage{{Params: params, Data: data}}, nil
+}
+
+// match 判断请求体是否满足丢弃条件，字段不存在时按空字符串比较
+func (cond MappingCondition) match(payload interface{}) (bool, error) {
+	value, found, err := lookupJSONPath(payload, cond.Path)
+	if err != nil {
+		return false, err
+	}
+	text := ""
+	if found {
+		text = jsonValueString(value)
+	}
+
+	if cond.Exists != nil && *cond.Exists != found {
+		return false, nil
+	}
+	if cond.Equals != nil && text != jsonValueString(cond.Equals) {
+		return false, nil
+	}
+	if cond.NotEquals != nil && text == jsonValueString(cond.NotEquals) {
+		return false, nil
+	}
+	if cond.In != nil {
+		matched := false
+		for _, candidate := range cond.In {
+			if text == jsonValueString(candidate) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if cond.Matches != "" {
+		re, err := regexp.Compile(cond.Matches)
+		if err != nil {
+			return false, err
+		}
+		if !re.MatchString(text) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// lookupJSONPath 按路径表达式查找 JSON 值，路径不存在时 found 为 false
+func lookupJSONPath(value interface{}, expr string) (result interface{}, found bool, err error) {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if value, found = v[segment]; !found {
+				return nil, false, nil
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, false, nil
+			}
+			if index < 0 {
+				index += len(v)
+			}
+			if index < 0 || index >= len(v) {
+				return nil, false, nil
+			}
+			value = v[index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// parseJSONPath 解析路径表达式，支持 $.a.b[0]、$['a.b']、a.b.0（省略 $）等写法，数组下标可以为负数
+func parseJSONPath(expr string) ([]string, error) {
+	rest := strings.TrimSpace(expr)
+	if rest == "" {
+		return nil, fmt.Errorf("路径表达式为空")
+	}
+	if strings.HasPrefix(rest, "$") {
+		rest = rest[1:]
+	} else {
+		rest = "." + rest
+	}
+
+	var segments []string
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("路径表达式 %q 格式错误", expr)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("路径表达式 %q 缺少 ]", expr)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				inner = inner[1 : len(inner)-1]
+			} else if _, err := strconv.Atoi(inner); err != nil {
+				return nil, fmt.Errorf("路径表达式 %q 的下标 %q 格式错误", expr, inner)
+			}
+			segments = append(segments, inner)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("路径表达式 %q 格式错误", expr)
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    []string
+		wantErr bool
+	}{
+		{expr: "$", want: nil},
+		{expr: "$.a.b", want: []string{"a", "b"}},
+		{expr: "a.b", want: []string{"a", "b"}},
+		{expr: " a.b ", want: []string{"a", "b"}},
+		{expr: "$.a[0].b", want: []string{"a", "0", "b"}},
+		{expr: "a.0.b", want: []string{"a", "0", "b"}},
+		{expr: "$.a[-1]", want: []string{"a", "-1"}},
+		{expr: "$['a.b']", want: []string{"a.b"}},
+		{expr: `$["a b"].c`, want: []string{"a b", "c"}},
+		{expr: "$[0][1]", want: []string{"0", "1"}},
+		{expr: "", wantErr: true},
+		{expr: "   ", wantErr: true},
+		{expr: "$.", wantErr: true},
+		{expr: "a..b", wantErr: true},
+		{expr: "$.a[0", wantErr: true},
+		{expr: "$.a[x]", wantErr: true},
+		{expr: "$a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseJSONPath(tt.expr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseJSONPath(%q) = %q, want error", tt.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseJSONPath(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseJSONPath(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	var doc interface{}
+	body := `{"a":{"b":"x","list":[1,"two",{"c":true}]},"a.b":"dotted","n":null}`
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr      string
+		want      interface{}
+		wantFound bool
+		wantErr   bool
+	}{
+		{expr: "$.a.b", want: "x", wantFound: true},
+		{expr: "a.list[0]", want: float64(1), wantFound: true},
+		{expr: "a.list.1", want: "two", wantFound: true},
+		{expr: "$.a.list[-1].c", want: true, wantFound: true},
+		{expr: "$.a.list[-3]", want: float64(1), wantFound: true},
+		{expr: "$['a.b']", want: "dotted", wantFound: true},
+		{expr: "$.n", want: nil, wantFound: true},
+		{expr: "$.a.missing"},
+		{expr: "$.a.list[3]"},
+		{expr: "$.a.list[-4]"},
+		{expr: "$.a.list.x"},
+		{expr: "$.a.b.c"},
+		{expr: "$.n.c"},
+		{expr: "$.a[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, found, err := lookupJSONPath(doc, tt.expr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("lookupJSONPath(%q) want error", tt.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("lookupJSONPath(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if found != tt.wantFound || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookupJSONPath(%q) = %v, %v, want %v, %v", tt.expr, got, found, tt.want, tt.wantFound)
+		}
+	}
+
+	if got, found, err := lookupJSONPath(doc, "$"); err != nil || !found || !reflect.DeepEqual(got, doc) {
+		t.Errorf("lookupJSONPath(\"$\") = %v, %v, %v, want root document", got, found, err)
+	}
+}
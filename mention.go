@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mentionConfig 机器人消息的提醒对象（@），钉钉和企业微信机器人共用
+type mentionConfig struct {
+	AtMobiles []string // 手机号
+	AtUserIds []string // 用户ID
+	AtAll     bool     // 提醒所有人
+}
+
+// mentionSchema 提醒对象的配置项
+func mentionSchema() []ConfigField {
+	return []ConfigField{
+		{Name: "AtMobiles", Description: "默认提醒的手机号列表（可选）"},
+		{Name: "AtUserIds", Description: "默认提醒的用户ID列表（可选）"},
+		{Name: "AtAll", Description: "默认提醒所有人（可选）"},
+	}
+}
+
+// convertToMentionConfig 读取配置中的默认提醒对象
+func convertToMentionConfig(config map[string]interface{}) (mentionConfig, error) {
+	var mention mentionConfig
+	var err error
+	if mention.AtMobiles, err = stringListConfig(config, "AtMobiles"); err != nil {
+		return mentionConfig{}, err
+	}
+	if mention.AtUserIds, err = stringListConfig(config, "AtUserIds"); err != nil {
+		return mentionConfig{}, err
+	}
+	if value, exists := config["AtAll"]; exists {
+		atAll, ok := value.(bool)
+		if !ok {
+			return mentionConfig{}, fmt.Errorf("配置 AtAll 格式错误")
+		}
+		mention.AtAll = atAll
+	}
+	return mention, nil
+}
+
+// stringListConfig 读取字符串列表配置项，未配置时返回空列表
+func stringListConfig(config map[string]interface{}, name string) ([]string, error) {
+	value, exists := config[name]
+	if !exists || value == nil {
+		return nil, nil
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("配置 %s 格式错误", name)
+	}
+	list := make([]string, len(items))
+	for i, item := range items {
+		text, ok := item.(string)
+		if !ok || text == "" {
+			return nil, fmt.Errorf("配置 %s 格式错误", name)
+		}
+		list[i] = text
+	}
+	return list, nil
+}
+
+// withParams 合并请求参数 at_mobiles、at_userids、at_all 中的提醒对象
+// 列表参数支持逗号分隔或 JSON 数组，at_all 为 true/false 时覆盖配置中的默认值
+func (m mentionConfig) withParams(params map[string]string) mentionConfig {
+	merged := mentionConfig{
+		AtMobiles: appendUnique(nil, m.AtMobiles...),
+		AtUserIds: appendUnique(nil, m.AtUserIds...),
+		AtAll:     m.AtAll,
+	}
+	merged.AtMobiles = appendUnique(merged.AtMobiles, splitListParam(params["at_mobiles"])...)
+	merged.AtUserIds = appendUnique(merged.AtUserIds, splitListParam(params["at_userids"])...)
+	if atAll, err := strconv.ParseBool(params["at_all"]); err == nil {
+		merged.AtAll = atAll
+	}
+	return merged
+}
+
+// handles 返回需要写入消息正文的 @ 标记，正文中已包含的会跳过
+func (m mentionConfig) handles(content string) string {
+	var handles []string
+	for _, id := range append(append([]string{}, m.AtMobiles...), m.AtUserIds...) {
+		if handle := "@" + id; !strings.Contains(content, handle) {
+			handles = append(handles, handle)
+		}
+	}
+	return strings.Join(handles, " ")
+}
+
+// splitListParam 解析列表参数，支持 JSON 数组和逗号分隔
+func splitListParam(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	var list []string
+	if strings.HasPrefix(value, "[") {
+		var items []interface{}
+		if json.Unmarshal([]byte(value), &items) == nil {
+			for _, item := range items {
+				if text := strings.TrimSpace(jsonValueString(item)); text != "" {
+					list = append(list, text)
+				}
+			}
+			return list
+		}
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// appendUnique 追加列表中尚未包含的值
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := false
+		for _, item := range list {
+			if item == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// quietWeekdays 星期名称
+var quietWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// QuietHoursConfig 免打扰时段配置，时段内的非紧急消息暂存到时段结束或丢弃
+type QuietHoursConfig struct {
+	Start    string   `json:"start"`    // 开始时间 HH:MM，与 end 都为空时表示全天
+	End      string   `json:"end"`      // 结束时间 HH:MM，不晚于开始时间时表示跨越午夜
+	Weekdays []string `json:"weekdays"` // 生效的星期（mon、tue ... sun，按开始时间所在日期计算），为空时每天生效
+	Timezone string   `json:"timezone"` // 时区，例如 Asia/Shanghai，默认使用系统时区
+	Action   string   `json:"action"`   // hold(默认) 时段结束后逐条发送; digest 时段结束后合并为一条发送; drop 直接丢弃
+}
+
+// validate 检查免打扰时段配置
+func (c *QuietHoursConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if (c.Start == "") != (c.End == "") {
+		return fmt.Errorf("免打扰时段 start 和 end 需要同时设置")
+	}
+	if _, err := parseClock(c.Start); err != nil {
+		return fmt.Errorf("免打扰时段 start 格式错误: %v", err)
+	}
+	if _, err := parseClock(c.End); err != nil {
+		return fmt.Errorf("免打扰时段 end 格式错误: %v", err)
+	}
+	for _, day := range c.Weekdays {
+		if _, ok := quietWeekdays[day]; !ok {
+			return fmt.Errorf("免打扰时段 weekdays 不支持: %s", day)
+		}
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("免打扰时段时区无效: %v", err)
+	}
+	if c.Action != "" && c.Action != "hold" && c.Action != "digest" && c.Action != "drop" {
+		return fmt.Errorf("不支持的免打扰处理方式: %s", c.Action)
+	}
+	return nil
+}
+
+// parseClock 解析 HH:MM 格式的时间，返回当天经过的分钟数，空字符串表示 0 点
+func parseClock(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// activeUntil 判断指定时间是否处于免打扰时段，返回时段结束时间；相邻的时段会合并
+func (c *QuietHoursConfig) activeUntil(now time.Time) (time.Time, bool) {
+	until, active := c.windowEnd(now)
+	if !active {
+		return time.Time{}, false
+	}
+	for i := 0; i < 7; i++ {
+		next, ok := c.windowEnd(until)
+		if !ok {
+			break
+		}
+		until = next
+	}
+	return until, true
+}
+
+// windowEnd 查找包含指定时间的单个免打扰时段，跨越午夜的时段从前一天开始
+func (c *QuietHoursConfig) windowEnd(now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, _ := parseClock(c.Start)
+	end, _ := parseClock(c.End)
+
+	now = now.In(loc)
+	for _, offset := range []int{-1, 0} {
+		day := time.Date(now.Year(), now.Month(), now.Day()+offset, 0, 0, 0, 0, loc)
+		if !c.onWeekday(day.Weekday()) {
+			continue
+		}
+
+		windowStart := day.Add(time.Duration(start) * time.Minute)
+		windowEnd := day.Add(time.Duration(end) * time.Minute)
+		if end <= start {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+		if !now.Before(windowStart) && now.Before(windowEnd) {
+			return windowEnd, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// onWeekday 判断免打扰时段是否在指定星期生效
+func (c *QuietHoursConfig) onWeekday(weekday time.Weekday) bool {
+	if len(c.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range c.Weekdays {
+		if quietWeekdays[day] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// holdQuietMessage 处理免打扰时段内的消息
+// hold 模式加入定时队列，使用模板渲染前的参数 rawParams 在时段结束后按完整流程发送；digest 模式加入汇总；drop 模式丢弃
+func holdQuietMessage(configPath string, config PushConfig, until time.Time, rawParams, params map[string]string, data map[string]interface{}) pushResponse {
+	untilText := until.Local().Format("2006-01-02 15:04:05")
+
+	var result string
+	var err error
+	switch config.QuietHours.Action {
+	case "drop":
+		result = "Dropped: 免打扰时段，消息已丢弃"
+		fmt.Printf("[%s] %s - %s\n", timestamp(), configPath, result)
+		return pushResponse{Status: http.StatusOK, Body: result}
+	case "digest":
+		var count int
+		if count, err = digestQueue.Hold(configPath, until, params); err == nil {
+			result = fmt.Sprintf("Buffered: 免打扰时段，已加入汇总，当前 %d 条，将于 %s 发送", count, untilText)
+		}
+	default:
+		var jobID string
+		if jobID, err = scheduleQueue.Add(configPath, until, rawParams, data); err == nil {
+			result = fmt.Sprintf("Held: 免打扰时段，将于 %s 发送 (%s)", untilText, jobID)
+		}
+	}
+
+	if err != nil {
+		ts := timestamp()
+		errorMsg := fmt.Sprintf("Error: 暂存免打扰时段消息失败: %v", err)
+
+		// 写入错误日志
+		writeErrorLog(ts, configPath, config.Type, errorMsg, params)
+
+		fmt.Printf("[%s] %s - %s\n", ts, configPath, errorMsg)
+		return pushResponse{Status: http.StatusInternalServerError, Body: errorMsg}
+	}
+	fmt.Printf("[%s] %s - %s\n", timestamp(), configPath, result)
+	return pushResponse{Status: http.StatusAccepted, Body: result}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// quietTime 返回 2026 年 10 月指定日期和时间的 UTC 时间，10 月 12 日为星期一
+func quietTime(day, hour, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestQuietHoursWindowEnd(t *testing.T) {
+	overnight := &QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}
+	fridayNight := &QuietHoursConfig{Start: "22:00", End: "07:00", Weekdays: []string{"fri"}, Timezone: "UTC"}
+	workHours := &QuietHoursConfig{Start: "09:00", End: "18:00", Weekdays: []string{"mon"}, Timezone: "UTC"}
+	allDay := &QuietHoursConfig{Timezone: "UTC"}
+	shanghai := &QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "Asia/Shanghai"}
+
+	tests := []struct {
+		name       string
+		config     *QuietHoursConfig
+		now        time.Time
+		want       time.Time
+		wantActive bool
+	}{
+		{name: "跨午夜时段开始当天", config: overnight, now: quietTime(12, 23, 0), want: quietTime(13, 7, 0), wantActive: true},
+		{name: "跨午夜时段次日凌晨", config: overnight, now: quietTime(13, 3, 0), want: quietTime(13, 7, 0), wantActive: true},
+		{name: "跨午夜时段开始时间", config: overnight, now: quietTime(12, 22, 0), want: quietTime(13, 7, 0), wantActive: true},
+		{name: "跨午夜时段结束时间不在时段内", config: overnight, now: quietTime(13, 7, 0)},
+		{name: "跨午夜时段开始之前", config: overnight, now: quietTime(12, 21, 59)},
+		{name: "跨午夜时段白天", config: overnight, now: quietTime(12, 12, 0)},
+		{name: "指定星期当晚", config: fridayNight, now: quietTime(16, 23, 0), want: quietTime(17, 7, 0), wantActive: true},
+		{name: "指定星期次日凌晨按开始日期计算", config: fridayNight, now: quietTime(17, 3, 0), want: quietTime(17, 7, 0), wantActive: true},
+		{name: "指定星期之外的晚上", config: fridayNight, now: quietTime(17, 23, 0)},
+		{name: "指定星期之外的凌晨", config: fridayNight, now: quietTime(16, 3, 0)},
+		{name: "当天时段", config: workHours, now: quietTime(12, 10, 0), want: quietTime(12, 18, 0), wantActive: true},
+		{name: "当天时段其他星期", config: workHours, now: quietTime(13, 10, 0)},
+		{name: "全天", config: allDay, now: quietTime(12, 10, 0), want: quietTime(13, 0, 0), wantActive: true},
+		{name: "时区", config: shanghai, now: quietTime(12, 15, 0), want: quietTime(12, 23, 0), wantActive: true},
+		{name: "时区之外", config: shanghai, now: quietTime(12, 13, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, active := tt.config.windowEnd(tt.now)
+			if active != tt.wantActive || !got.Equal(tt.want) {
+				t.Errorf("windowEnd(%s) = %s, %v, want %s, %v", tt.now, got, active, tt.want, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestQuietHoursActiveUntil(t *testing.T) {
+	weekend := &QuietHoursConfig{Weekdays: []string{"sat", "sun"}, Timezone: "UTC"}
+	overnight := &QuietHoursConfig{Start: "22:00", End: "07:00", Timezone: "UTC"}
+
+	tests := []struct {
+		name       string
+		config     *QuietHoursConfig
+		now        time.Time
+		want       time.Time
+		wantActive bool
+	}{
+		{name: "相邻的全天时段合并", config: weekend, now: quietTime(17, 10, 0), want: quietTime(19, 0, 0), wantActive: true},
+		{name: "最后一个时段", config: weekend, now: quietTime(18, 10, 0), want: quietTime(19, 0, 0), wantActive: true},
+		{name: "不在时段内", config: weekend, now: quietTime(16, 10, 0)},
+		{name: "不相邻的时段不合并", config: overnight, now: quietTime(12, 23, 0), want: quietTime(13, 7, 0), wantActive: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, active := tt.config.activeUntil(tt.now)
+			if active != tt.wantActive || !got.Equal(tt.want) {
+				t.Errorf("activeUntil(%s) = %s, %v, want %s, %v", tt.now, got, active, tt.want, tt.wantActive)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitPer      = 60
+	defaultRateLimitMaxQueue = 1000
+)
+
+// RateLimitConfig 令牌桶限流配置
+type RateLimitConfig struct {
+	Rate     int    `json:"rate"`      // 每个周期允许发送的消息数
+	Per      int    `json:"per"`       // 周期（秒），默认 60
+	Burst    int    `json:"burst"`     // 突发容量，默认等于 rate
+	Mode     string `json:"mode"`      // 超限处理方式: reject(默认) 返回429; queue 暂存到内存等待发送
+	MaxQueue int    `json:"max_queue"` // queue 模式下最多暂存的消息数，默认 1000
+}
+
+// validate 检查限流配置
+func (c *RateLimitConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Rate <= 0 {
+		return fmt.Errorf("限流配置 rate 必须大于 0")
+	}
+	if c.Mode != "" && c.Mode != "reject" && c.Mode != "queue" {
+		return fmt.Errorf("不支持的限流模式: %s", c.Mode)
+	}
+	return nil
+}
+
+// rateLimitError 限流拒绝错误
+type rateLimitError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("触发限流 (%s)，请在 %d 秒后重试", e.Scope, int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	mu     sync.Mutex
+	config RateLimitConfig
+	scope  string
+	tokens float64
+	last   time.Time
+	held   int // queue 模式下正在等待发送的消息数
+}
+
+// newTokenBucket 创建装满令牌的令牌桶
+func newTokenBucket(scope string, config RateLimitConfig) *tokenBucket {
+	b := &tokenBucket{config: config, scope: scope, last: time.Now()}
+	b.tokens = b.burst()
+	return b
+}
+
+// ratePerSecond 每秒补充的令牌数
+func (b *tokenBucket) ratePerSecond() float64 {
+	per := b.config.Per
+	if per <= 0 {
+		per = defaultRateLimitPer
+	}
+	return float64(b.config.Rate) / float64(per)
+}
+
+// burst 令牌桶容量
+func (b *tokenBucket) burst() float64 {
+	if b.config.Burst > 0 {
+		return float64(b.config.Burst)
+	}
+	return float64(b.config.Rate)
+}
+
+// refill 按经过的时间补充令牌，调用方需持有锁
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst(), b.tokens+now.Sub(b.last).Seconds()*b.ratePerSecond())
+	b.last = now
+}
+
+// take 尝试取出一个令牌，失败时返回需要等待的时间
+func (b *tokenBucket) take() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return b.waitFor(1 - b.tokens), false
+}
+
+// refund 归还一个令牌
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst(), b.tokens+1)
+}
+
+// reserve 预约一个令牌（允许透支），返回预约的令牌可用前需要等待的时间
+func (b *tokenBucket) reserve() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	maxQueue := b.config.MaxQueue
+	if maxQueue <= 0 {
+		maxQueue = defaultRateLimitMaxQueue
+	}
+
+	b.refill(time.Now())
+	if b.tokens < 1 && b.held >= maxQueue {
+		return b.waitFor(1 - b.tokens), false
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0, true
+	}
+	b.held++
+	return b.waitFor(-b.tokens), true
+}
+
+// release 预约等待的消息发送后释放暂存计数
+func (b *tokenBucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.held--
+}
+
+// waitFor 补充指定数量令牌所需的时间，调用方需持有锁
+func (b *tokenBucket) waitFor(tokens float64) time.Duration {
+	return time.Duration(tokens / b.ratePerSecond() * float64(time.Second))
+}
+
+// 全局令牌桶，按 "config:配置名" 和 "type:推送类型" 区分
+var (
+	rateLimiters      = make(map[string]*tokenBucket)
+	rateLimitersMutex sync.Mutex
+)
+
+// getTokenBucket 获取令牌桶，配置发生变化（例如热重载）时重新创建
+func getTokenBucket(key string, config RateLimitConfig) *tokenBucket {
+	rateLimitersMutex.Lock()
+	defer rateLimitersMutex.Unlock()
+
+	bucket, exists := rateLimiters[key]
+	if !exists || bucket.config != config {
+		bucket = newTokenBucket(key, config)
+		rateLimiters[key] = bucket
+	}
+	return bucket
+}
+
+// applyRateLimit 检查配置和推送类型的限流
+// reject 模式的令牌桶为空时返回 rateLimitError；queue 模式返回发送前需要等待的时间及需要在发送后释放的令牌桶
+// allowQueue 为 false 时 queue 模式也按 reject 模式处理
+func applyRateLimit(configName string, config PushConfig, allowQueue bool) (time.Duration, []*tokenBucket, error) {
+	var buckets []*tokenBucket
+	if config.RateLimit != nil {
+		buckets = append(buckets, getTokenBucket("config:"+configName, *config.RateLimit))
+	}
+	if typeLimit, ok := getConfigManager().RateLimits[config.Type]; ok && typeLimit != nil {
+		buckets = append(buckets, getTokenBucket("type:"+config.Type, *typeLimit))
+	}
+
+	// 先处理 reject 模式，任意一个令牌桶为空即拒绝，并归还已取出的令牌
+	var taken []*tokenBucket
+	for _, bucket := range buckets {
+		if bucket.config.Mode == "queue" && allowQueue {
+			continue
+		}
+		if retryAfter, ok := bucket.take(); !ok {
+			for _, b := range taken {
+				b.refund()
+			}
+			return 0, nil, &rateLimitError{Scope: bucket.scope, RetryAfter: retryAfter}
+		}
+		taken = append(taken, bucket)
+	}
+
+	// 再处理 queue 模式，等待时间取各令牌桶中的最大值
+	var wait time.Duration
+	var reserved, held []*tokenBucket
+	for _, bucket := range buckets {
+		if bucket.config.Mode != "queue" || !allowQueue {
+			continue
+		}
+		bucketWait, ok := bucket.reserve()
+		if !ok {
+			for _, b := range append(taken, reserved...) {
+				b.refund()
+			}
+			for _, b := range held {
+				b.release()
+			}
+			return 0, nil, &rateLimitError{Scope: bucket.scope + " 暂存已满", RetryAfter: bucketWait}
+		}
+		reserved = append(reserved, bucket)
+		if bucketWait > 0 {
+			held = append(held, bucket)
+		}
+		if bucketWait > wait {
+			wait = bucketWait
+		}
+	}
+
+	return wait, held, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// errMissingRoute 配置文件缺少全局路由
+var errMissingRoute = errors.New("配置文件中缺少 'route' 字段或值为空")
+
+// 防止信号和文件变更检测同时触发重载
+var reloadMutex sync.Mutex
+
+// LoadConfig 加载配置文件并完成校验
+func LoadConfig(file string) (*ConfigManager, error) {
+	configManager, err := NewConfigManager(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if configManager.Route == "" {
+		return nil, errMissingRoute
+	}
+
+	if err := configManager.Validate(); err != nil {
+		return nil, fmt.Errorf("配置校验失败: %v", err)
+	}
+
+	return configManager, nil
+}
+
+// ReloadConfig 重新加载配置文件，校验通过后原子替换当前配置，失败时保留原配置
+func ReloadConfig(file string) error {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	newConfig, err := LoadConfig(file)
+	if err != nil {
+		fmt.Printf("[%s] 配置重载失败，继续使用原配置: %v\n", timestamp(), err)
+		return err
+	}
+
+	oldConfig := currentConfig.Swap(newConfig)
+
+	// 心跳检测配置变化时重启心跳检测服务
+	if oldConfig == nil || oldConfig.HeartbeatURL != newConfig.HeartbeatURL || oldConfig.HeartbeatInterval != newConfig.HeartbeatInterval {
+		if heartbeat != nil {
+			heartbeat.Stop()
+		}
+		heartbeat = &HeartbeatService{
+			URL:      newConfig.HeartbeatURL,
+			Interval: newConfig.HeartbeatInterval,
+		}
+		heartbeat.Start()
+	}
+
+	fmt.Printf("[%s] 配置重载成功，共 %d 个推送配置\n", timestamp(), len(newConfig.Configs))
+	return nil
+}
+
+// watchConfigSignal 收到 SIGHUP 信号时重载配置
+func watchConfigSignal(file string) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for range signals {
+			fmt.Printf("[%s] 收到 SIGHUP 信号，重新加载配置\n", timestamp())
+			ReloadConfig(file)
+		}
+	}()
+}
+
+// watchConfigFile 定期检查配置文件修改时间，发生变化时重载配置
+func watchConfigFile(file string, interval int) {
+	lastModTime := time.Time{}
+	if info, err := os.Stat(file); err == nil {
+		lastModTime = info.ModTime()
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			info, err := os.Stat(file)
+			if err != nil {
+				fmt.Printf("[%s] 检查配置文件失败: %v\n", timestamp(), err)
+				continue
+			}
+			if info.ModTime().Equal(lastModTime) {
+				continue
+			}
+
+			lastModTime = info.ModTime()
+			fmt.Printf("[%s] 检测到配置文件变更，重新加载配置\n", timestamp())
+			ReloadConfig(file)
+		}
+	}()
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+)
+
+// 默认请求体大小上限（1MB）
+const defaultMaxBodySize = 1 << 20
+
+// multipart 表单解析时保存在内存中的最大字节数，超出部分写入临时文件
+const maxMultipartMemory = 32 << 20
+
+// reservedParams 由 infopush 内部设置的参数，忽略请求中的同名参数：
+// files 为上传文件的信息，telegram_progress 为重试时 Telegram 消息的发送进度
+var reservedParams = []string{"files", "telegram_progress"}
+
+// errBodyTooLarge 请求体超过大小上限
+var errBodyTooLarge = errors.New("请求体过大")
+
+// parseRequest 获取请求中的所有参数
+// 查询字符串和表单参数同名时取第一个值；JSON 请求体中的字段会覆盖同名查询参数，
+// 嵌套对象和数组以 JSON 字符串形式放入 params，同时完整保留在模板数据 data 中
+func parseRequest(r *http.Request, excluded ...string) (map[string]string, map[string]interface{}, error) {
+	var body map[string]interface{}
+	if isJSONRequest(r) {
+		var err error
+		if body, err = readJSONBody(r); err != nil {
+			return nil, nil, err
+		}
+	} else if err := parseForm(r); err != nil {
+		return nil, nil, err
+	}
+
+	params := make(map[string]string, len(r.Form)+len(body)+1)
+	for key, values := range r.Form {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	// 忽略查询字符串、表单字段和 JSON 请求体中的内部参数
+	for _, key := range reservedParams {
+		delete(params, key)
+	}
+
+	// multipart 表单上传的文件保存到上传目录，文件信息以 JSON 字符串形式放入 files 参数，供支持附件的渠道使用
+	files, err := readMultipartFiles(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if files != "" {
+		params["files"] = files
+	}
+
+	var data map[string]interface{}
+	if body != nil {
+		data = make(map[string]interface{}, len(params)+len(body))
+		for key, value := range params {
+			data[key] = value
+		}
+		for key, value := range body {
+			if isReservedParam(key) {
+				continue
+			}
+			params[key] = jsonValueString(value)
+			data[key] = value
+		}
+	}
+
+	for _, key := range excluded {
+		delete(params, key)
+		delete(data, key)
+	}
+	return params, data, nil
+}
+
+// isReservedParam 判断参数是否为内部参数
+func isReservedParam(key string) bool {
+	for _, reserved := range reservedParams {
+		if key == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// parseForm 解析查询字符串和表单（包括 multipart 表单），请求体超过大小上限时返回 errBodyTooLarge
+// 其他解析错误沿用 FormValue 的处理方式，忽略后使用已解析的参数
+func parseForm(r *http.Request) error {
+	err := r.ParseMultipartForm(maxMultipartMemory)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errBodyTooLarge
+	}
+	return nil
+}
+
+// readMultipartFiles 保存 multipart 表单中上传的所有文件，返回按字段名和上传顺序排列的文件信息
+func readMultipartFiles(r *http.Request) (string, error) {
+	if r.MultipartForm == nil || len(r.MultipartForm.File) == 0 {
+		return "", nil
+	}
+
+	fields := make([]string, 0, len(r.MultipartForm.File))
+	for field := range r.MultipartForm.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var files []uploadedFile
+	for _, field := range fields {
+		for _, header := range r.MultipartForm.File[field] {
+			file, err := header.Open()
+			if err != nil {
+				return "", fmt.Errorf("读取上传文件失败: %v", err)
+			}
+			sum, err := saveUpload(file)
+			file.Close()
+			if err != nil {
+				return "", fmt.Errorf("保存上传文件失败: %v", err)
+			}
+			files = append(files, uploadedFile{
+				Field:       field,
+				Name:        header.Filename,
+				ContentType: header.Header.Get("Content-Type"),
+				SHA256:      sum,
+			})
+		}
+	}
+
+	encoded, err := json.Marshal(files)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// isJSONRequest 判断请求体是否为 JSON
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || mediaType == "text/json")
+}
+
+// readJSONBody 读取 JSON 请求体，只接受 JSON 对象；读取后放回请求体供 webhook 适配器等再次使用
+func readJSONBody(r *http.Request) (map[string]interface{}, error) {
+	// 解析查询字符串参数，JSON 请求不会解析表单
+	r.ParseForm()
+
+	raw, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var body map[string]interface{}
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("解析JSON请求体失败: %v", err)
+	}
+	return body, nil
+}
+
+// readBody 读取完整请求体并放回，超过大小上限时返回 errBodyTooLarge
+func readBody(r *http.Request) ([]byte, error) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, errBodyTooLarge
+		}
+		return nil, fmt.Errorf("读取请求体失败: %v", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(raw))
+	return raw, nil
+}
+
+// jsonValueString 将 JSON 值转换为字符串参数
+func jsonValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 5
+	defaultRetryBaseDelay   = 10
+	defaultRetryMaxDelay    = 3600
+)
+
+// retryJob 重试队列中的消息
+type retryJob struct {
+	ID          string            `json:"id"`
+	ConfigName  string            `json:"config_name"`
+	Group       string            `json:"group,omitempty"` // 群发中失败的目标所属的群发配置，重试时使用目标自身的模板
+	Params      map[string]string `json:"params"`
+	Attempts    int               `json:"attempts"`
+	CreatedAt   time.Time         `json:"created_at"`
+	NextAttempt time.Time         `json:"next_attempt"`
+	LastError   string            `json:"last_error"`
+}
+
+// RetryQueue 持久化的失败消息重试队列
+type RetryQueue struct {
+	mu   sync.Mutex
+	file string
+	jobs []*retryJob
+}
+
+// 全局重试队列
+var retryQueue *RetryQueue
+
+// NewRetryQueue 创建重试队列并加载上次未完成的消息
+func NewRetryQueue(file string) (*RetryQueue, error) {
+	q := &RetryQueue{file: file}
+	if err := loadJSONFile(file, &q.jobs); err != nil {
+		return nil, fmt.Errorf("读取重试队列失败: %v", err)
+	}
+	return q, nil
+}
+
+// Len 返回队列中等待重试的消息数量
+func (q *RetryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// allParams 返回队列中所有消息的参数
+func (q *RetryQueue) allParams() []map[string]string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	params := make([]map[string]string, len(q.jobs))
+	for i, job := range q.jobs {
+		params[i] = job.Params
+	}
+	return params
+}
+
+// Enqueue 将发送失败的消息加入重试队列
+func (q *RetryQueue) Enqueue(configName string, retry *RetryConfig, params map[string]string, lastErr error) (string, error) {
+	return q.add(&retryJob{ConfigName: configName, Params: params}, retry, lastErr)
+}
+
+// EnqueueTarget 将群发中发送失败的单个目标加入重试队列
+func (q *RetryQueue) EnqueueTarget(group, target string, retry *RetryConfig, params map[string]string, lastErr error) (string, error) {
+	return q.add(&retryJob{ConfigName: target, Group: group, Params: params}, retry, lastErr)
+}
+
+// add 记录首次失败并保存到队列，已部分送达的消息只重试剩余部分
+func (q *RetryQueue) add(job *retryJob, retry *RetryConfig, lastErr error) (string, error) {
+	now := time.Now()
+	job.ID = newID()
+	job.Params = retryParams(job.Params, lastErr)
+	job.Attempts = 1
+	job.CreatedAt = now
+	job.LastError = lastErr.Error()
+	job.NextAttempt = now.Add(retryBackoff(retry, job.Attempts))
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs = append(q.jobs, job)
+	if err := q.save(); err != nil {
+		q.jobs = q.jobs[:len(q.jobs)-1]
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Start 启动后台重试任务
+func (q *RetryQueue) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			q.processDue()
+		}
+	}()
+}
+
+// processDue 重试所有已到期的消息
+func (q *RetryQueue) processDue() {
+	now := time.Now()
+
+	q.mu.Lock()
+	var due []*retryJob
+	for _, job := range q.jobs {
+		if !job.NextAttempt.After(now) {
+			due = append(due, job)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, job := range due {
+		q.retry(job)
+	}
+}
+
+// retry 重试单条消息并更新队列状态
+func (q *RetryQueue) retry(job *retryJob) {
+	config, exists := getConfigManager().GetConfig(job.ConfigName)
+	retry := jobRetryConfig(job)
+	if !exists || retry == nil {
+		q.giveUp(job, "unknown", "配置不存在或已关闭重试")
+		return
+	}
+
+	// 触发 queue 模式限流时消息会延后发送，通过上下文带上原任务，延后发送失败时保留已重试次数和创建时间
+	ctx := context.WithValue(context.Background(), retryJobKey{}, job)
+	var result string
+	var err error
+	if job.Group != "" {
+		result, err = sendToTarget(ctx, job.ConfigName, job.Params)
+	} else {
+		result, err = sendToConfig(ctx, job.ConfigName, job.Params)
+	}
+	if err == nil && strings.HasPrefix(result, "Delayed:") {
+		fmt.Printf("[%s] %s - 第%d次重试: %s\n", timestamp(), job.ConfigName, job.Attempts, strings.TrimSpace(strings.TrimPrefix(result, "Delayed:")))
+		q.remove(job.ID)
+		return
+	}
+	if err == nil {
+		fmt.Printf("[%s] %s - 第%d次重试成功: %s\n", timestamp(), job.ConfigName, job.Attempts, result)
+		q.remove(job.ID)
+		return
+	}
+
+	// 群发失败的目标已各自加入重试队列，不再重试整个群发
+	if groupErr, ok := asGroupError(err); ok && groupErr.Queued {
+		fmt.Printf("[%s] %s - 第%d次重试未全部成功，失败的目标已加入重试队列\n", timestamp(), job.ConfigName, job.Attempts)
+		q.remove(job.ID)
+		return
+	}
+
+	q.fail(job, config.Type, retry, err)
+}
+
+// Requeue 将重试时延后发送又失败的消息以新的任务ID放回队列，保留已重试次数和创建时间
+func (q *RetryQueue) Requeue(job *retryJob, err error) {
+	requeued := *job
+	requeued.ID = newID()
+
+	retry := jobRetryConfig(&requeued)
+	config, exists := getConfigManager().GetConfig(requeued.ConfigName)
+	if !exists || retry == nil {
+		requeued.LastError = err.Error()
+		q.giveUp(&requeued, "unknown", "配置不存在或已关闭重试")
+		return
+	}
+
+	q.mu.Lock()
+	q.jobs = append(q.jobs, &requeued)
+	q.mu.Unlock()
+	q.fail(&requeued, config.Type, retry, err)
+}
+
+// fail 记录一次重试失败，超过重试限制时放弃，否则按退避间隔安排下一次重试
+func (q *RetryQueue) fail(job *retryJob, platform string, retry *RetryConfig, err error) {
+	ts := timestamp()
+	errorMsg := fmt.Sprintf("第%d次重试失败: %v", job.Attempts, err)
+	writeErrorLog(ts, job.ConfigName, platform, errorMsg, job.Params)
+	fmt.Printf("[%s] %s - %s\n", ts, job.ConfigName, errorMsg)
+
+	q.mu.Lock()
+	job.Attempts++
+	job.LastError = err.Error()
+	job.Params = retryParams(job.Params, err)
+	q.mu.Unlock()
+
+	if isInvalidParam(err) {
+		q.giveUp(job, platform, "请求参数错误")
+		return
+	}
+
+	maxAttempts, maxAge := retryLimits(retry)
+	if maxAttempts > 0 && job.Attempts > maxAttempts {
+		q.giveUp(job, platform, fmt.Sprintf("已达到最大重试次数 %d", maxAttempts))
+		return
+	}
+	if maxAge > 0 && time.Since(job.CreatedAt) > maxAge {
+		q.giveUp(job, platform, fmt.Sprintf("已超过最长保留时间 %s", maxAge))
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job.NextAttempt = time.Now().Add(retryBackoff(retry, job.Attempts))
+	if err := q.save(); err != nil {
+		fmt.Printf("[%s] 保存重试队列失败: %v\n", timestamp(), err)
+	}
+}
+
+// retryJobKey 上下文中正在重试的任务
+type retryJobKey struct{}
+
+// retryJobFrom 返回上下文中正在重试的任务，只匹配该任务自身的配置，群发或故障转移的下级目标不受影响
+func retryJobFrom(ctx context.Context, configName string) *retryJob {
+	job, _ := ctx.Value(retryJobKey{}).(*retryJob)
+	if job == nil || job.ConfigName != configName {
+		return nil
+	}
+	return job
+}
+
+// jobRetryConfig 返回消息使用的重试配置，群发目标未配置重试时使用群发的重试配置
+func jobRetryConfig(job *retryJob) *RetryConfig {
+	cm := getConfigManager()
+	if config, exists := cm.GetConfig(job.ConfigName); exists && config.Retry != nil {
+		return config.Retry
+	}
+	if job.Group != "" {
+		if group, exists := cm.GetConfig(job.Group); exists {
+			return group.Retry
+		}
+	}
+	return nil
+}
+
+// giveUp 放弃重试并记录错误日志
+func (q *RetryQueue) giveUp(job *retryJob, platform, reason string) {
+	ts := timestamp()
+	errorMsg := fmt.Sprintf("放弃重试 (%s)，最后错误: %s", reason, job.LastError)
+	writeErrorLog(ts, job.ConfigName, platform, errorMsg, job.Params)
+	fmt.Printf("[%s] %s - %s\n", ts, job.ConfigName, errorMsg)
+	q.remove(job.ID)
+}
+
+// remove 从队列中移除消息
+func (q *RetryQueue) remove(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.jobs {
+		if job.ID == id {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+	}
+	if err := q.save(); err != nil {
+		fmt.Printf("[%s] 保存重试队列失败: %v\n", timestamp(), err)
+	}
+}
+
+// save 将队列写入文件，调用方需持有锁
+func (q *RetryQueue) save() error {
+	jobs := q.jobs
+	if jobs == nil {
+		jobs = []*retryJob{}
+	}
+	return saveJSONFile(q.file, jobs)
+}
+
+// retryLimits 返回最大重试次数和最长保留时间，两者都未配置时使用默认重试次数
+func retryLimits(retry *RetryConfig) (int, time.Duration) {
+	maxAttempts := retry.MaxAttempts
+	maxAge := time.Duration(retry.MaxAge) * time.Second
+	if maxAttempts <= 0 && maxAge <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+	return maxAttempts, maxAge
+}
+
+// retryBackoff 计算第 attempt 次重试前的等待时间（指数退避 + 随机抖动）
+func retryBackoff(retry *RetryConfig, attempt int) time.Duration {
+	baseDelay := retry.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	maxDelay := retry.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	delay := time.Duration(baseDelay) * time.Second
+	for i := 1; i < attempt && delay < time.Duration(maxDelay)*time.Second; i++ {
+		delay *= 2
+	}
+	if delay > time.Duration(maxDelay)*time.Second {
+		delay = time.Duration(maxDelay) * time.Second
+	}
+
+	// 在 [delay/2, delay] 之间随机取值，避免大量消息同时重试
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
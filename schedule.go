@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scheduledJob 定时发送的消息
+type scheduledJob struct {
+	ID         string                 `json:"id"`
+	ConfigName string                 `json:"config_name"`
+	SendAt     time.Time              `json:"send_at"`
+	CreatedAt  time.Time              `json:"created_at"`
+	Params     map[string]string      `json:"params"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+// ScheduleQueue 持久化的定时消息队列
+type ScheduleQueue struct {
+	mu   sync.Mutex
+	file string
+	jobs []*scheduledJob
+}
+
+// 全局定时消息队列
+var scheduleQueue *ScheduleQueue
+
+// NewScheduleQueue 创建定时消息队列并加载上次未发送的消息
+func NewScheduleQueue(file string) (*ScheduleQueue, error) {
+	q := &ScheduleQueue{file: file}
+	if err := loadJSONFile(file, &q.jobs); err != nil {
+		return nil, fmt.Errorf("读取定时消息队列失败: %v", err)
+	}
+	return q, nil
+}
+
+// Len 返回队列中等待发送的消息数量
+func (q *ScheduleQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
+
+// allParams 返回队列中所有消息的参数
+func (q *ScheduleQueue) allParams() []map[string]string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	params := make([]map[string]string, len(q.jobs))
+	for i, job := range q.jobs {
+		params[i] = job.Params
+	}
+	return params
+}
+
+// Add 加入一条定时消息，返回任务ID
+func (q *ScheduleQueue) Add(configName string, sendAt time.Time, params map[string]string, data map[string]interface{}) (string, error) {
+	job := &scheduledJob{
+		ID:         newID(),
+		ConfigName: configName,
+		SendAt:     sendAt,
+		CreatedAt:  time.Now(),
+		Params:     params,
+		Data:       data,
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.jobs = append(q.jobs, job)
+	if err := q.save(); err != nil {
+		q.jobs = q.jobs[:len(q.jobs)-1]
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// List 返回指定配置等待发送的消息，按发送时间排序
+func (q *ScheduleQueue) List(configName string) []scheduledJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := []scheduledJob{}
+	for _, job := range q.jobs {
+		if job.ConfigName == configName {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].SendAt.Before(jobs[j].SendAt)
+	})
+	return jobs
+}
+
+// Cancel 取消指定配置的定时消息，任务不存在时返回 false
+func (q *ScheduleQueue) Cancel(configName, id string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, job := range q.jobs {
+		if job.ID == id && job.ConfigName == configName {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			return true, q.save()
+		}
+	}
+	return false, nil
+}
+
+// Start 启动后台定时发送任务
+func (q *ScheduleQueue) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			q.processDue()
+		}
+	}()
+}
+
+// processDue 发送所有已到时间的消息，发送前从队列中移除
+func (q *ScheduleQueue) processDue() {
+	now := time.Now()
+
+	q.mu.Lock()
+	var due, pending []*scheduledJob
+	for _, job := range q.jobs {
+		if job.SendAt.After(now) {
+			pending = append(pending, job)
+		} else {
+			due = append(due, job)
+		}
+	}
+	if len(due) > 0 {
+		q.jobs = pending
+		if err := q.save(); err != nil {
+			fmt.Printf("[%s] 保存定时消息队列失败: %v\n", timestamp(), err)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, job := range due {
+		config, exists := getConfigManager().GetConfig(job.ConfigName)
+		if !exists {
+			ts := timestamp()
+			errorMsg := fmt.Sprintf("配置不存在，丢弃定时消息 %s", job.ID)
+			writeErrorLog(ts, job.ConfigName, "unknown", errorMsg, job.Params)
+			fmt.Printf("[%s] %s - %s\n", ts, job.ConfigName, errorMsg)
+			continue
+		}
+
+		fmt.Printf("[%s] %s - 发送定时消息 %s\n", timestamp(), job.ConfigName, job.ID)
+		deliverMessage(job.ConfigName, config, job.Params, job.Data)
+	}
+}
+
+// save 将队列写入文件，调用方需持有锁
+func (q *ScheduleQueue) save() error {
+	jobs := q.jobs
+	if jobs == nil {
+		jobs = []*scheduledJob{}
+	}
+	return saveJSONFile(q.file, jobs)
+}
+
+// parseSendTime 读取并移除 send_at 或 delay 参数，返回计划发送时间，未指定时返回零值
+// send_at 支持 RFC3339 格式和 Unix 时间戳（秒或毫秒），delay 支持 Go 时长格式（例如 30m、1h30m）或秒数
+func parseSendTime(params map[string]string, data map[string]interface{}) (time.Time, error) {
+	sendAt, delay := strings.TrimSpace(params["send_at"]), strings.TrimSpace(params["delay"])
+	delete(params, "send_at")
+	delete(params, "delay")
+	delete(data, "send_at")
+	delete(data, "delay")
+
+	switch {
+	case sendAt != "" && delay != "":
+		return time.Time{}, fmt.Errorf("send_at 和 delay 不能同时使用")
+	case sendAt != "":
+		if unix, err := strconv.ParseInt(sendAt, 10, 64); err == nil {
+			if unix > 1e12 {
+				return time.UnixMilli(unix), nil
+			}
+			return time.Unix(unix, 0), nil
+		}
+		t, err := time.Parse(time.RFC3339, sendAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("send_at 格式错误，应为 RFC3339 或 Unix 时间戳: %s", sendAt)
+		}
+		return t, nil
+	case delay != "":
+		if seconds, err := strconv.Atoi(delay); err == nil && seconds >= 0 {
+			return time.Now().Add(time.Duration(seconds) * time.Second), nil
+		}
+		d, err := time.ParseDuration(delay)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("delay 格式错误，应为秒数或时长（例如 30m）: %s", delay)
+		}
+		return time.Now().Add(d), nil
+	}
+	return time.Time{}, nil
+}
+
+// parseSchedulePath 解析定时消息管理路径 配置名/scheduled 和 配置名/scheduled/任务ID
+func parseSchedulePath(configPath string) (name, jobID string, ok bool) {
+	if name, found := strings.CutSuffix(configPath, "/scheduled"); found && name != "" {
+		return name, "", true
+	}
+	if i := strings.LastIndex(configPath, "/scheduled/"); i > 0 {
+		jobID = configPath[i+len("/scheduled/"):]
+		if jobID != "" && !strings.Contains(jobID, "/") {
+			return configPath[:i], jobID, true
+		}
+	}
+	return "", "", false
+}
+
+// handleScheduleRequest 处理定时消息管理请求: GET 列出等待发送的消息，DELETE 取消指定的消息
+func handleScheduleRequest(w http.ResponseWriter, r *http.Request, configPath, jobID string) {
+	switch {
+	case jobID == "" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(scheduleQueue.List(configPath))
+
+	case jobID != "" && r.Method == http.MethodDelete:
+		canceled, err := scheduleQueue.Cancel(configPath, jobID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error: 保存定时消息队列失败: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !canceled {
+			http.Error(w, "定时消息不存在", http.StatusNotFound)
+			return
+		}
+		fmt.Printf("[%s] %s - 已取消定时消息 %s\n", timestamp(), configPath, jobID)
+		fmt.Fprintf(w, "Canceled: %s", jobID)
+
+	default:
+		if jobID == "" {
+			w.Header().Set("Allow", http.MethodGet)
+		} else {
+			w.Header().Set("Allow", http.MethodDelete)
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
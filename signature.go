@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultSignatureWindow = 300
+
+// SignatureConfig 请求签名校验配置
+type SignatureConfig struct {
+	Secret string `json:"secret"` // 签名密钥
+	Window int    `json:"window"` // 允许的时间戳偏差（秒），默认 300
+}
+
+// 已使用过的签名，在时间窗口内拒绝重复使用
+var (
+	usedSignatures      = make(map[string]time.Time)
+	usedSignaturesMutex sync.Mutex
+)
+
+// computeSignature 按钉钉机器人加签规则计算签名：Base64(HmacSHA256(timestamp + "\n" + content))
+func computeSignature(secret, timestamp, content string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + content))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature 校验请求签名，返回 0 表示通过，否则返回应响应的HTTP状态码和原因
+// 签名内容为毫秒时间戳、规范化的查询字符串和请求体，时间戳和签名可通过 X-Timestamp/X-Sign 请求头或 timestamp/sign 查询参数提供
+func verifySignature(r *http.Request, config *SignatureConfig) (int, string) {
+	ts := r.Header.Get("X-Timestamp")
+	if ts == "" {
+		ts = r.URL.Query().Get("timestamp")
+	}
+	sign := r.Header.Get("X-Sign")
+	if sign == "" {
+		sign = r.URL.Query().Get("sign")
+	}
+	if ts == "" || sign == "" {
+		return http.StatusUnauthorized, "缺少签名或时间戳"
+	}
+
+	// 校验时间戳是否在允许的时间窗口内
+	millis, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return http.StatusForbidden, "时间戳格式错误"
+	}
+	window := time.Duration(config.Window) * time.Second
+	if window <= 0 {
+		window = defaultSignatureWindow * time.Second
+	}
+	skew := time.Since(time.UnixMilli(millis))
+	if skew > window || skew < -window {
+		return http.StatusForbidden, fmt.Sprintf("时间戳超出允许范围 (偏差 %s)", skew.Round(time.Second))
+	}
+
+	// 读取请求体后放回，供后续解析表单使用
+	body, err := readBody(r)
+	if errors.Is(err, errBodyTooLarge) {
+		return http.StatusRequestEntityTooLarge, err.Error()
+	}
+	if err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+
+	expected := computeSignature(config.Secret, ts, canonicalQuery(r)+"\n"+string(body))
+	if !hmac.Equal([]byte(sign), []byte(expected)) {
+		return http.StatusForbidden, "签名错误"
+	}
+
+	// 拒绝时间窗口内的重放请求
+	usedSignaturesMutex.Lock()
+	defer usedSignaturesMutex.Unlock()
+
+	now := time.Now()
+	for usedSign, expiresAt := range usedSignatures {
+		if now.After(expiresAt) {
+			delete(usedSignatures, usedSign)
+		}
+	}
+	if _, used := usedSignatures[sign]; used {
+		return http.StatusForbidden, "签名已被使用"
+	}
+	usedSignatures[sign] = now.Add(2 * window)
+
+	return 0, ""
+}
+
+// canonicalQuery 返回参与签名的查询字符串：去掉 timestamp 和 sign 后按参数名排序编码
+func canonicalQuery(r *http.Request) string {
+	query := r.URL.Query()
+	query.Del("timestamp")
+	query.Del("sign")
+	return query.Encode()
+}
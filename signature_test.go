@@ -0,0 +1,181 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedRequest 创建带签名的请求，inQuery 为 true 时时间戳和签名放在查询参数中
+func signedRequest(secret, query, body string, offset time.Duration, inQuery bool) *http.Request {
+	ts := strconv.FormatInt(time.Now().Add(offset).UnixMilli(), 10)
+	values, _ := url.ParseQuery(query)
+	sign := computeSignature(secret, ts, values.Encode()+"\n"+body)
+
+	target := "/test"
+	if inQuery {
+		values.Set("timestamp", ts)
+		values.Set("sign", sign)
+	}
+	if len(values) > 0 {
+		target += "?" + values.Encode()
+	}
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if !inQuery {
+		r.Header.Set("X-Timestamp", ts)
+		r.Header.Set("X-Sign", sign)
+	}
+	return r
+}
+
+func TestVerifySignature(t *testing.T) {
+	config := &SignatureConfig{Secret: "secret"}
+	shortWindow := &SignatureConfig{Secret: "secret", Window: 10}
+
+	tests := []struct {
+		name       string
+		config     *SignatureConfig
+		request    func() *http.Request
+		wantStatus int
+	}{
+		{
+			name:   "请求头签名",
+			config: config,
+			request: func() *http.Request {
+				return signedRequest("secret", "", "msg=header", 0, false)
+			},
+		},
+		{
+			name:   "查询参数签名",
+			config: config,
+			request: func() *http.Request {
+				return signedRequest("secret", "title=t&msg=query", "", 0, true)
+			},
+		},
+		{
+			name:   "查询参数顺序不影响签名",
+			config: config,
+			request: func() *http.Request {
+				r := signedRequest("secret", "b=2&a=1", "order", 0, false)
+				r.URL.RawQuery = "b=2&a=1"
+				return r
+			},
+		},
+		{
+			name:   "时间窗口内的过去时间",
+			config: config,
+			request: func() *http.Request {
+				return signedRequest("secret", "", "past", -4*time.Minute, false)
+			},
+		},
+		{
+			name:   "自定义时间窗口内",
+			config: shortWindow,
+			request: func() *http.Request {
+				return signedRequest("secret", "", "short", -5*time.Second, false)
+			},
+		},
+		{
+			name:   "缺少签名",
+			config: config,
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("missing"))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "时间戳格式错误",
+			config: config,
+			request: func() *http.Request {
+				r := signedRequest("secret", "", "bad timestamp", 0, false)
+				r.Header.Set("X-Timestamp", "abc")
+				return r
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "时间戳早于默认时间窗口",
+			config: config,
+			request: func() *http.Request {
+				return signedRequest("secret", "", "expired", -6*time.Minute, false)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "时间戳晚于默认时间窗口",
+			config: config,
+			request: func() *http.Request {
+				return signedRequest("secret", "", "future", 6*time.Minute, false)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "超出自定义时间窗口",
+			config: shortWindow,
+			request: func() *http.Request {
+				return signedRequest("secret", "", "short expired", -20*time.Second, false)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "密钥错误",
+			config: config,
+			request: func() *http.Request {
+				return signedRequest("other", "", "wrong secret", 0, false)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "请求体被修改",
+			config: config,
+			request: func() *http.Request {
+				r := signedRequest("secret", "", "original", 0, false)
+				r.Body = io.NopCloser(strings.NewReader("tampered"))
+				return r
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "查询参数被修改",
+			config: config,
+			request: func() *http.Request {
+				r := signedRequest("secret", "msg=original", "", 0, true)
+				r.URL.RawQuery = strings.Replace(r.URL.RawQuery, "original", "tampered", 1)
+				return r
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason := verifySignature(tt.request(), tt.config)
+			if status != tt.wantStatus {
+				t.Errorf("verifySignature() = %d %q, want %d", status, reason, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureReplay(t *testing.T) {
+	config := &SignatureConfig{Secret: "secret"}
+	r := signedRequest("secret", "", "replay", 0, false)
+	replay := r.Clone(r.Context())
+	replay.Body = io.NopCloser(strings.NewReader("replay"))
+
+	if status, reason := verifySignature(r, config); status != 0 {
+		t.Fatalf("第一次请求 verifySignature() = %d %q, want 0", status, reason)
+	}
+	// 校验后请求体应可再次读取
+	if body, _ := io.ReadAll(r.Body); string(body) != "replay" {
+		t.Errorf("校验后请求体 = %q, want %q", body, "replay")
+	}
+	if status, _ := verifySignature(replay, config); status != http.StatusForbidden {
+		t.Errorf("重放请求 verifySignature() = %d, want %d", status, http.StatusForbidden)
+	}
+}
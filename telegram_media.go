@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Telegram 图片和文件说明的长度上限（UTF-16 编码单位）
+const telegramMaxCaptionLength = 1024
+
+// Telegram 一组媒体消息最多包含的文件数
+const telegramMaxMediaGroup = 10
+
+// telegramMediaItem 要发送的单个文件，上传的文件或由 Telegram 自行下载的 URL
+type telegramMediaItem struct {
+	URL  string
+	File multipartFile
+}
+
+// telegramInputMedia 媒体组中的单个文件
+type telegramInputMedia struct {
+	Type            string           `json:"type"`
+	Media           string           `json:"media"`
+	Caption         string           `json:"caption,omitempty"`
+	ParseMode       string           `json:"parse_mode,omitempty"`
+	CaptionEntities []telegramEntity `json:"caption_entities,omitempty"`
+}
+
+func init() {
+	RegisterChannel(NewChannel("telegram_photo",
+		telegramSchema(),
+		func(configData map[string]interface{}) error {
+			_, err := convertToTelegramTextConfig(configData)
+			return err
+		},
+		SendTelegramPhoto,
+	))
+	RegisterChannel(NewChannel("telegram_document",
+		telegramSchema(),
+		func(configData map[string]interface{}) error {
+			_, err := convertToTelegramTextConfig(configData)
+			return err
+		},
+		SendTelegramDocument,
+	))
+}
+
+// SendTelegramPhoto 发送Telegram图片消息 - 统一接口，多张图片以相册形式发送
+func SendTelegramPhoto(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	return sendTelegramMedia(ctx, configName, configData, params, "photo")
+}
+
+// SendTelegramDocument 发送Telegram文件消息 - 统一接口，多个文件以媒体组形式发送
+func SendTelegramDocument(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+	return sendTelegramMedia(ctx, configName, configData, params, "document")
+}
+
+// sendTelegramMedia 发送上传的文件和 url 参数指定的文件，msg 作为说明文字
+// 说明文字超过长度上限时，文件不带说明发送，随后以文本消息发送完整内容
+func sendTelegramMedia(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string, mediaType string) (string, error) {
+	// 转换配置
+	config, err := convertToTelegramTextConfig(configData)
+	if err != nil {
+		return "", err
+	}
+
+	// 合并请求参数中的发送选项
+	options, err := config.Options.withParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := telegramMediaFiles(params)
+	if err != nil {
+		return "", err
+	}
+
+	// 获取说明文字，标题显示为加粗的第一行
+	caption, entities := telegramText(params["title"], params["msg"], options)
+	var messages []telegramMessage
+	if utf16Len(caption) > telegramMaxCaptionLength {
+		messages = telegramMessages(params["title"], params["msg"], options)
+		caption, entities = "", nil
+	}
+
+	// 每个聊天依次发送各组文件和完整内容的文本消息，重试时跳过已完成的部分
+	batches := (len(files) + telegramMaxMediaGroup - 1) / telegramMaxMediaGroup
+	return sendTelegramChats(params, config.ChatIDs, func(chatID string, skip int) (string, int, error) {
+		sent, err := sendTelegramMediaFiles(ctx, configName, config, chatID, mediaType, files, caption, entities, options, skip)
+		if err != nil {
+			return "", sent, err
+		}
+		if len(messages) > 0 {
+			url := fmt.Sprintf("%s/bot%s/sendMessage", config.APIBaseURL, config.Token)
+			result, sentMessages, err := sendTelegramTextMessages(ctx, configName, url, chatID, messages, options, max(skip-batches, 0))
+			return result, batches + sentMessages, err
+		}
+		return "Success", batches, nil
+	})
+}
+
+// sendTelegramMediaFiles 向一个聊天发送文件，单个文件调用 sendPhoto/sendDocument，
+// 多个文件调用 sendMediaGroup，每组最多 10 个，说明文字显示在第一个文件上
+// 跳过前 skip 组，返回已发送的组数（包括跳过的）
+func sendTelegramMediaFiles(ctx context.Context, configName string, config TelegramTextConfig, chatID, mediaType string, files []telegramMediaItem, caption string, entities []telegramEntity, options telegramOptions, skip int) (int, error) {
+	platform, singleMethod := "Telegram图片", "sendPhoto"
+	if mediaType == "document" {
+		platform, singleMethod = "Telegram文件", "sendDocument"
+	}
+
+	for batch, start := 0, 0; start < len(files); batch, start = batch+1, start+telegramMaxMediaGroup {
+		if batch < skip {
+			continue
+		}
+		end := start + telegramMaxMediaGroup
+		if end > len(files) {
+			end = len(files)
+		}
+		group := files[start:end]
+		if start > 0 {
+			caption, entities = "", nil
+		}
+
+		// URL 直接交给 Telegram 下载，只有上传的文件作为 multipart 文件发送
+		var uploads []multipartFile
+		fields := telegramMediaFields(chatID, options)
+		method := "sendMediaGroup"
+		if len(group) == 1 {
+			// 单个文件，说明文字作为请求字段
+			method = singleMethod
+			if group[0].URL != "" {
+				fields[mediaType] = group[0].URL
+			} else {
+				file := group[0].File
+				file.Field = mediaType
+				uploads = append(uploads, file)
+			}
+			if caption != "" {
+				fields["caption"] = caption
+				if options.ParseMode != "" {
+					fields["parse_mode"] = options.ParseMode
+				}
+				if len(entities) > 0 {
+					encoded, err := json.Marshal(entities)
+					if err != nil {
+						return batch, err
+					}
+					fields["caption_entities"] = string(encoded)
+				}
+			}
+		} else {
+			// 媒体组，上传的文件通过 attach:// 引用
+			media := make([]telegramInputMedia, len(group))
+			for i, item := range group {
+				media[i] = telegramInputMedia{Type: mediaType, Media: item.URL}
+				if item.URL == "" {
+					file := item.File
+					file.Field = fmt.Sprintf("file%d", i)
+					uploads = append(uploads, file)
+					media[i].Media = "attach://" + file.Field
+				}
+			}
+			if caption != "" {
+				media[0].Caption = caption
+				media[0].ParseMode = options.ParseMode
+				media[0].CaptionEntities = entities
+			}
+			encoded, err := json.Marshal(media)
+			if err != nil {
+				return batch, err
+			}
+			fields["media"] = string(encoded)
+		}
+
+		url := fmt.Sprintf("%s/bot%s/%s", config.APIBaseURL, config.Token, method)
+		_, err := sendTelegramRequest(ctx, configName, platform, func() ([]byte, error) {
+			return httpMultipartRequest(ctx, url, fields, uploads, 120*time.Second)
+		})
+		if err != nil {
+			if len(files) > telegramMaxMediaGroup {
+				return batch, fmt.Errorf("第 %d-%d 个文件发送失败: %v", start+1, end, err)
+			}
+			return batch, err
+		}
+	}
+	return (len(files) + telegramMaxMediaGroup - 1) / telegramMaxMediaGroup, nil
+}
+
+// telegramMediaFields 媒体消息共用的请求字段
+func telegramMediaFields(chatID string, options telegramOptions) map[string]string {
+	fields := map[string]string{"chat_id": chatID}
+	if options.MessageThreadID != 0 {
+		fields["message_thread_id"] = strconv.FormatInt(options.MessageThreadID, 10)
+	}
+	if options.DisableNotification {
+		fields["disable_notification"] = "true"
+	}
+	if options.ProtectContent {
+		fields["protect_content"] = "true"
+	}
+	return fields
+}
+
+// telegramMediaFiles 获取要发送的文件：先是 multipart 上传的文件，然后是 url 参数（逗号分隔或 JSON 数组）指定的文件
+// url 参数只支持 http/https，由 Telegram 服务器自行下载，infopush 不会请求这些地址
+func telegramMediaFiles(params map[string]string) ([]telegramMediaItem, error) {
+	uploads, err := parseUploadedFiles(params["files"])
+	if err != nil {
+		return nil, err
+	}
+	files := make([]telegramMediaItem, 0, len(uploads))
+	for _, upload := range uploads {
+		file, err := upload.load()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, telegramMediaItem{File: file})
+	}
+
+	for _, fileURL := range splitListParam(params["url"]) {
+		if !strings.HasPrefix(fileURL, "http://") && !strings.HasPrefix(fileURL, "https://") {
+			return nil, invalidParamf("url参数只支持 http/https 地址: %s", fileURL)
+		}
+		files = append(files, telegramMediaItem{URL: fileURL})
+	}
+
+	if len(files) == 0 {
+		return nil, invalidParamf("缺少文件，请通过 multipart 表单上传文件或提供url参数")
+	}
+	return files, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// Telegram 单条消息的长度上限（UTF-16 编码单位）
+const telegramMaxTextLength = 4096
+
+// 拆分长消息时为分段序号和补全的格式标记预留的长度
+const telegramSplitReserve = 128
+
+// telegramMessage 拆分后的一条消息
+type telegramMessage struct {
+	Text     string
+	Entities []telegramEntity
+}
+
+// telegramMessages 生成要发送的消息，超过长度上限时按行拆分为带序号的多条消息
+// 标题只出现在第一条；跨越拆分位置的 HTML 标签和 MarkdownV2 格式标记会在前一条末尾闭合、后一条开头重新打开
+func telegramMessages(title, msg string, options telegramOptions) []telegramMessage {
+	text, entities := telegramText(title, msg, options)
+	if utf16Len(text) <= telegramMaxTextLength {
+		return []telegramMessage{{Text: text, Entities: entities}}
+	}
+
+	header, _ := telegramText(title, "", options)
+	body := strings.TrimPrefix(text, header)
+
+	limit := telegramMaxTextLength - telegramSplitReserve - utf16Len(header)
+	if limit < telegramMaxTextLength/4 {
+		limit = telegramMaxTextLength / 4
+	}
+	chunks := splitTelegramLines(body, limit, options.ParseMode)
+
+	messages := make([]telegramMessage, len(chunks))
+	state := newTelegramFormatState(options.ParseMode)
+	for i, chunk := range chunks {
+		number := telegramEscape(fmt.Sprintf("(%d/%d) ", i+1, len(chunks)), options.ParseMode)
+		reopen := state.reopen()
+		state.scan(chunk)
+
+		part := telegramMessage{Text: number + reopen + chunk + state.close()}
+		if i == 0 {
+			part.Text = number + header + reopen + chunk + state.close()
+			for _, entity := range entities {
+				entity.Offset += utf16Len(number)
+				part.Entities = append(part.Entities, entity)
+			}
+		}
+		messages[i] = part
+	}
+	return messages
+}
+
+// splitTelegramLines 按行将文本组合成不超过 limit 的片段，单行超长时在不破坏转义和标签的位置截断
+func splitTelegramLines(text string, limit int, parseMode string) []string {
+	var chunks []string
+	current, currentLen, empty := "", 0, true
+	for _, line := range strings.Split(text, "\n") {
+		for i, piece := range splitTelegramLine(line, limit, parseMode) {
+			pieceLen := utf16Len(piece)
+			// 放不下或是超长行拆出的后续片段时另起一条
+			if !empty && (i > 0 || currentLen+1+pieceLen > limit) {
+				chunks = append(chunks, current)
+				current, currentLen, empty = "", 0, true
+			}
+			if !empty {
+				current += "\n"
+				currentLen++
+			}
+			current += piece
+			currentLen += pieceLen
+			empty = false
+		}
+	}
+	return append(chunks, current)
+}
+
+// splitTelegramLine 将超长的一行拆成多段，不会拆开 HTML 标签、HTML 实体和 MarkdownV2 转义
+func splitTelegramLine(line string, limit int, parseMode string) []string {
+	if utf16Len(line) <= limit {
+		return []string{line}
+	}
+
+	var pieces []string
+	start, length, safe := 0, 0, 0
+	inTag, inEntity, escaped := false, false, false
+	for i, r := range line {
+		// 当前位置可以截断时记录下来
+		if !inTag && !inEntity && !escaped && i > start {
+			safe = i
+		}
+
+		size := len(utf16.Encode([]rune{r}))
+		if length+size > limit && safe > start {
+			pieces = append(pieces, line[start:safe])
+			length = utf16Len(line[safe:i])
+			start = safe
+		}
+		length += size
+
+		switch parseMode {
+		case "HTML":
+			switch {
+			case r == '<':
+				inTag = true
+			case r == '>':
+				inTag = false
+			case r == '&' && !inTag:
+				inEntity = true
+			case r == ';' || r == ' ':
+				inEntity = false
+			}
+		case "MarkdownV2":
+			escaped = !escaped && r == '\\'
+		}
+	}
+	return append(pieces, line[start:])
+}
+
+// telegramHTMLTag 匹配 HTML 开始和结束标签
+var telegramHTMLTag = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)[^>]*>`)
+
+// telegramFormatState 记录拆分位置仍未闭合的格式标记
+type telegramFormatState struct {
+	parseMode string
+	tags      []telegramOpenTag // HTML 未闭合的标签
+	markers   []string          // MarkdownV2 未闭合的行内标记
+	pre       string            // MarkdownV2 未闭合代码块的开始标记，例如 ```go
+	code      bool              // MarkdownV2 未闭合的行内代码
+}
+
+// telegramOpenTag 未闭合的 HTML 标签
+type telegramOpenTag struct {
+	Name string
+	Open string
+}
+
+// newTelegramFormatState 创建格式状态
+func newTelegramFormatState(parseMode string) *telegramFormatState {
+	return &telegramFormatState{parseMode: parseMode}
+}
+
+// scan 扫描一段文本，更新未闭合的格式标记
+func (s *telegramFormatState) scan(text string) {
+	switch s.parseMode {
+	case "HTML":
+		for _, match := range telegramHTMLTag.FindAllStringSubmatch(text, -1) {
+			name := strings.ToLower(match[2])
+			if match[1] == "" {
+				s.tags = append(s.tags, telegramOpenTag{Name: name, Open: match[0]})
+				continue
+			}
+			for i := len(s.tags) - 1; i >= 0; i-- {
+				if s.tags[i].Name == name {
+					s.tags = append(s.tags[:i], s.tags[i+1:]...)
+					break
+				}
+			}
+		}
+	case "MarkdownV2":
+		s.scanMarkdownV2(text)
+	}
+}
+
+// scanMarkdownV2 扫描 MarkdownV2 文本，跳过转义字符，代码块和行内代码中的标记不生效
+func (s *telegramFormatState) scanMarkdownV2(text string) {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case strings.HasPrefix(text[i:], "```"):
+			if s.pre != "" {
+				s.pre = ""
+			} else {
+				line, _, _ := strings.Cut(text[i:], "\n")
+				s.pre = line
+			}
+			i += 2
+		case s.pre != "":
+		case text[i] == '`':
+			s.code = !s.code
+		case s.code:
+		default:
+			marker := string(text[i])
+			if strings.HasPrefix(text[i:], "__") || strings.HasPrefix(text[i:], "||") {
+				marker = text[i : i+2]
+			} else if !strings.ContainsAny(marker, "*_~") {
+				continue
+			}
+			i += len(marker) - 1
+			s.toggle(marker)
+		}
+	}
+}
+
+// toggle 打开或闭合 MarkdownV2 行内标记
+func (s *telegramFormatState) toggle(marker string) {
+	for i := len(s.markers) - 1; i >= 0; i-- {
+		if s.markers[i] == marker {
+			s.markers = append(s.markers[:i], s.markers[i+1:]...)
+			return
+		}
+	}
+	s.markers = append(s.markers, marker)
+}
+
+// close 返回闭合所有未闭合标记的文本
+func (s *telegramFormatState) close() string {
+	var b strings.Builder
+	for i := len(s.tags) - 1; i >= 0; i-- {
+		b.WriteString("</" + s.tags[i].Name + ">")
+	}
+	if s.pre != "" {
+		b.WriteString("\n```")
+	}
+	if s.code {
+		b.WriteString("`")
+	}
+	for i := len(s.markers) - 1; i >= 0; i-- {
+		b.WriteString(s.markers[i])
+	}
+	return b.String()
+}
+
+// reopen 返回重新打开所有未闭合标记的文本
+func (s *telegramFormatState) reopen() string {
+	var b strings.Builder
+	for _, tag := range s.tags {
+		b.WriteString(tag.Open)
+	}
+	for _, marker := range s.markers {
+		b.WriteString(marker)
+	}
+	if s.code {
+		b.WriteString("`")
+	}
+	if s.pre != "" {
+		b.WriteString(s.pre + "\n")
+	}
+	return b.String()
+}
+
+// utf16Len 返回文本的 UTF-16 编码长度，Telegram 按此计算消息长度和实体偏移
+func utf16Len(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
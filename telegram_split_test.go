@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitTelegramLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		limit     int
+		parseMode string
+		want      []string
+	}{
+		{name: "短行不拆分", line: "abc", limit: 4, want: []string{"abc"}},
+		{name: "纯文本按长度拆分", line: "abcdefghij", limit: 4, want: []string{"abcd", "efgh", "ij"}},
+		{name: "按UTF-16计算长度", line: "😀😀😀", limit: 4, want: []string{"😀😀", "😀"}},
+		{name: "不拆开HTML实体", line: "ab&amp;cd", limit: 4, parseMode: "HTML", want: []string{"ab", "&amp;", "cd"}},
+		{name: "不拆开HTML标签", line: "x<b>yz</b>", limit: 3, parseMode: "HTML", want: []string{"x", "<b>", "yz", "</b>"}},
+		{name: "不拆开MarkdownV2转义", line: `ab\.cd`, limit: 3, parseMode: "MarkdownV2", want: []string{"ab", `\.c`, "d"}},
+		{name: "纯文本不识别标签", line: "x<b>yz", limit: 3, want: []string{"x<b", ">yz"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitTelegramLine(tt.line, tt.limit, tt.parseMode)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitTelegramLine(%q, %d, %q) = %q, want %q", tt.line, tt.limit, tt.parseMode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelegramFormatState(t *testing.T) {
+	tests := []struct {
+		name       string
+		parseMode  string
+		chunks     []string
+		wantClose  string
+		wantReopen string
+	}{
+		{name: "HTML嵌套标签", parseMode: "HTML", chunks: []string{"<b>bold <i>it"}, wantClose: "</i></b>", wantReopen: "<b><i>"},
+		{name: "HTML已闭合的标签", parseMode: "HTML", chunks: []string{`<a href="x">link</a> <b>`}, wantClose: "</b>", wantReopen: "<b>"},
+		{name: "HTML标签不区分大小写", parseMode: "HTML", chunks: []string{"<B>x</b>"}},
+		{name: "HTML跨片段闭合", parseMode: "HTML", chunks: []string{"<b>a", "b</b>"}},
+		{name: "MarkdownV2行内标记", parseMode: "MarkdownV2", chunks: []string{"*bold _it"}, wantClose: "_*", wantReopen: "*_"},
+		{name: "MarkdownV2转义字符", parseMode: "MarkdownV2", chunks: []string{`\*not bold`}},
+		{name: "MarkdownV2双字符标记", parseMode: "MarkdownV2", chunks: []string{"__u__ ||s"}, wantClose: "||", wantReopen: "||"},
+		{name: "MarkdownV2代码块", parseMode: "MarkdownV2", chunks: []string{"```go\ncode *x"}, wantClose: "\n```", wantReopen: "```go\n"},
+		{name: "MarkdownV2行内代码", parseMode: "MarkdownV2", chunks: []string{"`co*de"}, wantClose: "`", wantReopen: "`"},
+		{name: "MarkdownV2标记和行内代码", parseMode: "MarkdownV2", chunks: []string{"*b `c"}, wantClose: "`*", wantReopen: "*`"},
+		{name: "MarkdownV2跨片段闭合", parseMode: "MarkdownV2", chunks: []string{"*a", "b*"}},
+		{name: "纯文本", chunks: []string{"<b>*x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := newTelegramFormatState(tt.parseMode)
+			for _, chunk := range tt.chunks {
+				state.scan(chunk)
+			}
+			if got := state.close(); got != tt.wantClose {
+				t.Errorf("close() = %q, want %q", got, tt.wantClose)
+			}
+			if got := state.reopen(); got != tt.wantReopen {
+				t.Errorf("reopen() = %q, want %q", got, tt.wantReopen)
+			}
+		})
+	}
+}
+
+func TestTelegramMessages(t *testing.T) {
+	short := telegramMessages("T", "hello", telegramOptions{ParseMode: "HTML"})
+	if len(short) != 1 || short[0].Text != "<b>T</b>\nhello" {
+		t.Errorf("telegramMessages 短消息 = %+v", short)
+	}
+
+	tests := []struct {
+		parseMode string
+		msg       string
+	}{
+		{parseMode: "", msg: strings.Repeat("line of plain text\n", 600)},
+		{parseMode: "HTML", msg: "<b>" + strings.Repeat("bold <i>line</i> &amp; more\n", 600) + "</b>"},
+		{parseMode: "MarkdownV2", msg: "*" + strings.Repeat("bold \\. _line_\n", 800) + "*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.parseMode, func(t *testing.T) {
+			messages := telegramMessages("T", tt.msg, telegramOptions{ParseMode: tt.parseMode})
+			if len(messages) < 2 {
+				t.Fatalf("telegramMessages 返回 %d 条消息，应拆分为多条", len(messages))
+			}
+			header, _ := telegramText("T", "", telegramOptions{ParseMode: tt.parseMode})
+			for i, message := range messages {
+				if n := utf16Len(message.Text); n > telegramMaxTextLength {
+					t.Errorf("第 %d 条消息长度 %d 超过上限", i+1, n)
+				}
+				number := telegramEscape(fmt.Sprintf("(%d/%d) ", i+1, len(messages)), tt.parseMode)
+				if !strings.HasPrefix(message.Text, number) {
+					t.Errorf("第 %d 条消息缺少序号 %q", i+1, number)
+				}
+				if hasHeader := strings.HasPrefix(message.Text[len(number):], header); hasHeader != (i == 0) {
+					t.Errorf("第 %d 条消息标题出现位置错误", i+1)
+				}
+				// 每条消息的格式标记都应闭合
+				state := newTelegramFormatState(tt.parseMode)
+				state.scan(message.Text)
+				if open := state.close(); open != "" {
+					t.Errorf("第 %d 条消息存在未闭合的格式标记 %q", i+1, open)
+				}
+			}
+		})
+	}
+}
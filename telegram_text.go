@@ -1,73 +1,468 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Telegram 返回 429 时按 retry_after 等待后重试的次数和可接受的最长等待时间
+const (
+	telegramMaxAttempts   = 3
+	telegramMaxRetryAfter = 30 * time.Second
+)
+
 // TelegramTextConfig Telegram Bot文本消息配置
 type TelegramTextConfig struct {
 	Token      string
-	ChatID     string
+	ChatIDs    []string
 	APIBaseURL string
+	Options    telegramOptions
+}
+
+// telegramOptions 消息格式和发送选项，配置中的值为默认值，可由同名请求参数覆盖
+type telegramOptions struct {
+	ParseMode             string // HTML 或 MarkdownV2，为空时发送纯文本
+	Escape                bool   // 转义 msg 中的格式字符，msg 本身已是 HTML/MarkdownV2 内容时关闭
+	DisableWebPagePreview bool   // 不显示链接预览
+	DisableNotification   bool   // 静默发送
+	ProtectContent        bool   // 禁止转发和保存
+	MessageThreadID       int64  // 论坛话题ID
+}
+
+// telegramEntity 消息中的格式实体，偏移和长度以 UTF-16 编码单位计算
+type telegramEntity struct {
+	Type   string `json:"type"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
 }
 
 // telegramTextRequest 发送文本消息的请求结构
 type telegramTextRequest struct {
-	ChatID string `json:"chat_id"`
-	Text   string `json:"text"`
+	ChatID                string           `json:"chat_id"`
+	MessageThreadID       int64            `json:"message_thread_id,omitempty"`
+	Text                  string           `json:"text"`
+	ParseMode             string           `json:"parse_mode,omitempty"`
+	Entities              []telegramEntity `json:"entities,omitempty"`
+	DisableWebPagePreview bool             `json:"disable_web_page_preview,omitempty"`
+	DisableNotification   bool             `json:"disable_notification,omitempty"`
+	ProtectContent        bool             `json:"protect_content,omitempty"`
+}
+
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("telegram_text",
+		telegramSchema(),
+		func(configData map[string]interface{}) error {
+			_, err := convertToTelegramTextConfig(configData)
+			return err
+		},
+		SendTelegramText,
+	), 4096))
 }
 
 // SendTelegramText 发送Telegram文本消息 - 统一接口
-func SendTelegramText(configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+func SendTelegramText(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
 	// 转换配置
 	config, err := convertToTelegramTextConfig(configData)
 	if err != nil {
 		return "", err
 	}
 
-	// 获取消息内容
-	message := params["msg"]
+	// 合并请求参数中的发送选项
+	options, err := config.Options.withParams(params)
+	if err != nil {
+		return "", err
+	}
+
+	// 获取消息内容，标题显示为加粗的第一行，超长时拆分为多条
+	messages := telegramMessages(params["title"], params["msg"], options)
 
 	// 构造API URL
 	url := fmt.Sprintf("%s/bot%s/sendMessage", config.APIBaseURL, config.Token)
 
-	// 构造请求数据
-	requestData := telegramTextRequest{
-		ChatID: config.ChatID,
-		Text:   message,
+	// 同一聊天内按顺序发送拆分后的消息
+	return sendTelegramChats(params, config.ChatIDs, func(chatID string, skip int) (string, int, error) {
+		return sendTelegramTextMessages(ctx, configName, url, chatID, messages, options, skip)
+	})
+}
+
+// telegramSchema Telegram 各消息类型共用的配置项
+func telegramSchema() []ConfigField {
+	return []ConfigField{
+		{Name: "Token", Required: true, Description: "Bot Token"},
+		{Name: "ChatID", Required: true, Description: "聊天ID，多个聊天使用数组"},
+		{Name: "APIBaseURL", Required: true, Description: "Telegram Bot API 地址"},
+		{Name: "ParseMode", Description: "消息格式 HTML 或 MarkdownV2（可选）"},
+		{Name: "Escape", Description: "转义消息中的格式字符，默认开启（可选）"},
+		{Name: "DisableWebPagePreview", Description: "不显示链接预览（可选）"},
+		{Name: "DisableNotification", Description: "静默发送（可选）"},
+		{Name: "ProtectContent", Description: "禁止转发和保存（可选）"},
+		{Name: "MessageThreadID", Description: "论坛话题ID（可选）"},
 	}
+}
 
-	jsonData, err := json.Marshal(requestData)
-	if err != nil {
+// telegramChatResult 单个聊天的发送结果，Sent 为已完成的步骤数（拆分后的消息或媒体组）
+type telegramChatResult struct {
+	ChatID string
+	Result string
+	Sent   int
+	Err    error
+}
+
+// sendTelegramChats 并发发送到所有聊天并汇总每个聊天的结果，全部成功才算成功
+// send 从第 skip 个步骤开始发送，返回结果和已完成的步骤数（包括跳过的）；
+// 部分送达时返回 partialSendError，重试只发送失败的聊天中未完成的步骤
+func sendTelegramChats(params map[string]string, chatIDs []string, send func(chatID string, skip int) (string, int, error)) (string, error) {
+	// 重试时只发送上次未完成的聊天
+	progress, resumed := telegramProgress(params)
+	if resumed {
+		var pending []string
+		for _, chatID := range chatIDs {
+			if _, ok := progress[chatID]; ok {
+				pending = append(pending, chatID)
+			}
+		}
+		chatIDs = pending
+	}
+
+	results := make([]telegramChatResult, len(chatIDs))
+	var wg sync.WaitGroup
+	for i, chatID := range chatIDs {
+		wg.Add(1)
+		go func(i int, chatID string) {
+			defer wg.Done()
+			result, sent, err := send(chatID, progress[chatID])
+			results[i] = telegramChatResult{ChatID: chatID, Result: result, Sent: sent, Err: err}
+		}(i, chatID)
+	}
+	wg.Wait()
+
+	succeeded, delivered := 0, resumed
+	remaining := make(map[string]int)
+	lines := make([]string, 0, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			remaining[r.ChatID] = r.Sent
+			delivered = delivered || r.Sent > 0
+			lines = append(lines, fmt.Sprintf("%s: Error: %v", r.ChatID, r.Err))
+			continue
+		}
+		succeeded++
+		delivered = true
+		lines = append(lines, fmt.Sprintf("%s: %s", r.ChatID, r.Result))
+	}
+
+	var err error
+	switch {
+	case len(results) == 0:
+		return "Success", nil
+	case len(remaining) == 0 && len(results) == 1:
+		return results[0].Result, nil
+	case len(remaining) == 0:
+		return strings.Join(lines, "\n"), nil
+	case len(results) == 1:
+		err = results[0].Err
+	default:
+		err = fmt.Errorf("Telegram 发送失败 (%d/%d 成功)\n%s", succeeded, len(results), strings.Join(lines, "\n"))
+	}
+	if !delivered {
 		return "", err
 	}
 
-	// 发送请求
-	response, err := httpRequest("POST", url, jsonData, 30*time.Second)
-	if err != nil {
+	encoded, encodeErr := json.Marshal(remaining)
+	if encodeErr != nil {
 		return "", err
 	}
+	return "", &partialSendError{err: err, Resume: map[string]string{"telegram_progress": string(encoded)}}
+}
+
+// telegramProgress 解析重试参数 telegram_progress 中各聊天已完成的步骤数
+func telegramProgress(params map[string]string) (map[string]int, bool) {
+	progress := make(map[string]int)
+	if params["telegram_progress"] == "" || json.Unmarshal([]byte(params["telegram_progress"]), &progress) != nil {
+		return map[string]int{}, false
+	}
+	return progress, true
+}
+
+// sendTelegramTextMessages 向一个聊天依次发送拆分后的消息，从第 skip 条开始，任意一条失败即停止
+// 返回结果和已发送的消息数（包括跳过的）
+func sendTelegramTextMessages(ctx context.Context, configName, url, chatID string, messages []telegramMessage, options telegramOptions, skip int) (string, int, error) {
+	for i := skip; i < len(messages); i++ {
+		message := messages[i]
+		// 构造请求数据
+		requestData := telegramTextRequest{
+			ChatID:                chatID,
+			MessageThreadID:       options.MessageThreadID,
+			Text:                  message.Text,
+			ParseMode:             options.ParseMode,
+			Entities:              message.Entities,
+			DisableWebPagePreview: options.DisableWebPagePreview,
+			DisableNotification:   options.DisableNotification,
+			ProtectContent:        options.ProtectContent,
+		}
+
+		jsonData, err := json.Marshal(requestData)
+		if err != nil {
+			return "", i, err
+		}
+
+		// 发送请求
+		_, err = sendTelegramRequest(ctx, configName, "Telegram文本", func() ([]byte, error) {
+			return httpRequest(ctx, "POST", url, jsonData, 30*time.Second)
+		})
+		if err != nil {
+			if len(messages) > 1 {
+				return "", i, fmt.Errorf("第 %d/%d 条发送失败: %v", i+1, len(messages), err)
+			}
+			return "", i, err
+		}
+	}
 
-	responseStr := string(response)
-	return handleAPIResponse(configName, "Telegram文本", responseStr, `"ok":true`)
+	if len(messages) > 1 {
+		return fmt.Sprintf("Success (拆分为 %d 条)", len(messages)), len(messages), nil
+	}
+	return "Success", len(messages), nil
+}
+
+// sendTelegramRequest 发送 Bot API 请求，返回 429 时按 retry_after 等待后重试，等待期间 ctx 取消则放弃
+func sendTelegramRequest(ctx context.Context, configName, platform string, request func() ([]byte, error)) (string, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := request()
+		if err != nil {
+			return "", err
+		}
+
+		responseStr := string(response)
+		wait, limited := telegramRetryAfter(response)
+		if !limited || attempt >= telegramMaxAttempts || wait > telegramMaxRetryAfter {
+			return handleAPIResponse(configName, platform, responseStr, `"ok":true`)
+		}
+
+		fmt.Printf("[%s] %s - %s触发限流，%d 秒后重试: %s\n", timestamp(), configName, platform, int(wait.Seconds()), responseStr)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// telegramRetryAfter 解析 429 响应中建议的等待时间
+func telegramRetryAfter(response []byte) (time.Duration, bool) {
+	var result struct {
+		ErrorCode  int `json:"error_code"`
+		Parameters struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if json.Unmarshal(response, &result) != nil || result.ErrorCode != 429 {
+		return 0, false
+	}
+	return time.Duration(result.Parameters.RetryAfter) * time.Second, true
 }
 
 // convertToTelegramTextConfig 将通用配置转换为Telegram文本配置
 func convertToTelegramTextConfig(config map[string]interface{}) (TelegramTextConfig, error) {
 	// 使用类型断言提取配置值
 	token, _ := config["Token"].(string)
-	chatID, _ := config["ChatID"].(string)
 	apiBaseURL, _ := config["APIBaseURL"].(string)
 
-	if token == "" || chatID == "" || apiBaseURL == "" {
+	if token == "" || config["ChatID"] == nil || apiBaseURL == "" {
 		return TelegramTextConfig{}, fmt.Errorf("缺少必要的Telegram配置参数")
 	}
 
+	chatIDs, err := convertToTelegramChatIDs(config["ChatID"])
+	if err != nil {
+		return TelegramTextConfig{}, err
+	}
+
+	options, err := convertToTelegramOptions(config)
+	if err != nil {
+		return TelegramTextConfig{}, err
+	}
+
 	return TelegramTextConfig{
 		Token:      token,
-		ChatID:     chatID,
+		ChatIDs:    chatIDs,
 		APIBaseURL: apiBaseURL,
+		Options:    options,
 	}, nil
 }
+
+// convertToTelegramChatIDs 读取聊天ID，支持单个ID或ID数组，ID可以写成字符串或数字
+func convertToTelegramChatIDs(value interface{}) ([]string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("配置 ChatID 不能为空")
+	}
+
+	chatIDs := make([]string, len(items))
+	for i, item := range items {
+		switch id := item.(type) {
+		case string:
+			chatIDs[i] = id
+		case float64:
+			chatIDs[i] = strconv.FormatInt(int64(id), 10)
+		}
+		if chatIDs[i] == "" {
+			return nil, fmt.Errorf("配置 ChatID 格式错误")
+		}
+	}
+	return chatIDs, nil
+}
+
+// convertToTelegramOptions 读取配置中的默认发送选项
+func convertToTelegramOptions(config map[string]interface{}) (telegramOptions, error) {
+	options := telegramOptions{Escape: true}
+
+	if value, exists := config["ParseMode"]; exists {
+		parseMode, ok := value.(string)
+		if !ok {
+			return telegramOptions{}, fmt.Errorf("配置 ParseMode 格式错误")
+		}
+		mode, err := normalizeTelegramParseMode(parseMode)
+		if err != nil {
+			return telegramOptions{}, err
+		}
+		options.ParseMode = mode
+	}
+
+	flags := map[string]*bool{
+		"Escape":                &options.Escape,
+		"DisableWebPagePreview": &options.DisableWebPagePreview,
+		"DisableNotification":   &options.DisableNotification,
+		"ProtectContent":        &options.ProtectContent,
+	}
+	for name, target := range flags {
+		value, exists := config[name]
+		if !exists {
+			continue
+		}
+		flag, ok := value.(bool)
+		if !ok {
+			return telegramOptions{}, fmt.Errorf("配置 %s 格式错误", name)
+		}
+		*target = flag
+	}
+
+	// 话题ID可以写成数字或字符串
+	switch value := config["MessageThreadID"].(type) {
+	case nil:
+	case float64:
+		options.MessageThreadID = int64(value)
+	case string:
+		threadID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return telegramOptions{}, fmt.Errorf("配置 MessageThreadID 格式错误")
+		}
+		options.MessageThreadID = threadID
+	default:
+		return telegramOptions{}, fmt.Errorf("配置 MessageThreadID 格式错误")
+	}
+
+	return options, nil
+}
+
+// withParams 使用请求参数 parse_mode、escape、disable_web_page_preview、disable_notification、
+// protect_content、message_thread_id 覆盖配置中的默认选项
+func (o telegramOptions) withParams(params map[string]string) (telegramOptions, error) {
+	if value := params["parse_mode"]; value != "" {
+		mode, err := normalizeTelegramParseMode(value)
+		if err != nil {
+			return telegramOptions{}, &invalidParamError{err: err}
+		}
+		o.ParseMode = mode
+	}
+
+	flags := map[string]*bool{
+		"escape":                   &o.Escape,
+		"disable_web_page_preview": &o.DisableWebPagePreview,
+		"disable_notification":     &o.DisableNotification,
+		"protect_content":          &o.ProtectContent,
+	}
+	for name, target := range flags {
+		value := params[name]
+		if value == "" {
+			continue
+		}
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return telegramOptions{}, invalidParamf("%s参数格式错误，应为 true 或 false", name)
+		}
+		*target = flag
+	}
+
+	if value := params["message_thread_id"]; value != "" {
+		threadID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return telegramOptions{}, invalidParamf("message_thread_id参数格式错误，应为整数")
+		}
+		o.MessageThreadID = threadID
+	}
+
+	return o, nil
+}
+
+// normalizeTelegramParseMode 规范化消息格式名称，只支持 HTML 和 MarkdownV2
+func normalizeTelegramParseMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		return "", nil
+	case "html":
+		return "HTML", nil
+	case "markdownv2":
+		return "MarkdownV2", nil
+	}
+	return "", fmt.Errorf("不支持的 Telegram 消息格式: %s，可选值为 HTML 或 MarkdownV2", mode)
+}
+
+// telegramText 生成消息正文，标题转义后加粗显示在第一行；
+// 纯文本消息通过 bold 实体加粗标题，返回的实体偏移基于最终正文
+func telegramText(title, msg string, options telegramOptions) (string, []telegramEntity) {
+	if options.Escape {
+		msg = telegramEscape(msg, options.ParseMode)
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return msg, nil
+	}
+
+	switch options.ParseMode {
+	case "HTML":
+		return "<b>" + telegramEscape(title, "HTML") + "</b>\n" + msg, nil
+	case "MarkdownV2":
+		return "*" + telegramEscape(title, "MarkdownV2") + "*\n" + msg, nil
+	}
+	entity := telegramEntity{Type: "bold", Offset: 0, Length: utf16Len(title)}
+	return title + "\n" + msg, []telegramEntity{entity}
+}
+
+// telegramMarkdownV2Replacer 转义 MarkdownV2 中所有需要转义的字符
+var telegramMarkdownV2Replacer = func() *strings.Replacer {
+	var pairs []string
+	for _, c := range `\_*[]()~` + "`" + `>#+-=|{}.!` {
+		pairs = append(pairs, string(c), `\`+string(c))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// telegramEscape 按消息格式转义文本，使其原样显示
+func telegramEscape(text, parseMode string) string {
+	switch parseMode {
+	case "HTML":
+		return html.EscapeString(text)
+	case "MarkdownV2":
+		return telegramMarkdownV2Replacer.Replace(text)
+	}
+	return text
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs 消息模板可用的辅助函数
+var templateFuncs = template.FuncMap{
+	"now":        time.Now,
+	"formatTime": formatTime,
+	"truncate":   truncate,
+	"default":    defaultValue,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+}
+
+// parseMessageTemplate 解析消息模板
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// validateTemplates 检查配置中的模板语法
+func (config PushConfig) validateTemplates() error {
+	if _, err := parseMessageTemplate("template", config.Template); err != nil {
+		return fmt.Errorf("消息模板错误: %v", err)
+	}
+	if _, err := parseMessageTemplate("title_template", config.TitleTemplate); err != nil {
+		return fmt.Errorf("标题模板错误: %v", err)
+	}
+	return nil
+}
+
+// applyTemplates 使用配置的模板渲染 msg 和 title，data 为空时使用 params 作为模板数据
+func applyTemplates(config PushConfig, params map[string]string, data map[string]interface{}) error {
+	if config.Template == "" && config.TitleTemplate == "" {
+		return nil
+	}
+
+	if data == nil {
+		data = make(map[string]interface{}, len(params))
+		for key, value := range params {
+			data[key] = value
+		}
+	}
+
+	// 标题和消息都基于渲染前的数据
+	var title, msg string
+	var err error
+	if config.TitleTemplate != "" {
+		if title, err = renderTemplate("title_template", config.TitleTemplate, data); err != nil {
+			return fmt.Errorf("渲染标题模板失败: %v", err)
+		}
+	}
+	if config.Template != "" {
+		if msg, err = renderTemplate("template", config.Template, data); err != nil {
+			return fmt.Errorf("渲染消息模板失败: %v", err)
+		}
+	}
+
+	if config.TitleTemplate != "" {
+		params["title"] = title
+	}
+	if config.Template != "" {
+		params["msg"] = msg
+	}
+	return nil
+}
+
+// renderTemplate 渲染单个模板
+func renderTemplate(name, text string, data map[string]interface{}) (string, error) {
+	tmpl, err := parseMessageTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// formatTime 格式化时间，支持 time.Time、Unix 时间戳（秒或毫秒）和 RFC3339 字符串
+// 用法: {{formatTime "2006-01-02 15:04:05" .time}}
+func formatTime(layout string, value interface{}) string {
+	var t time.Time
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		t = v
+	case json.Number:
+		unix, err := v.Int64()
+		if err != nil {
+			return v.String()
+		}
+		t = unixTime(unix)
+	case float64:
+		t = unixTime(int64(v))
+	case int64:
+		t = unixTime(v)
+	case int:
+		t = unixTime(int64(v))
+	case string:
+		if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+			t = unixTime(unix)
+		} else if parsed, err := time.Parse(time.RFC3339, v); err == nil {
+			t = parsed
+		} else {
+			return v
+		}
+	default:
+		return fmt.Sprint(value)
+	}
+	return t.Local().Format(layout)
+}
+
+// unixTime 将秒或毫秒时间戳转换为时间
+func unixTime(unix int64) time.Time {
+	if unix > 1e12 {
+		return time.UnixMilli(unix)
+	}
+	return time.Unix(unix, 0)
+}
+
+// truncate 按字符数截断文本，超出部分以 ... 代替
+// 用法: {{truncate 100 .msg}}
+func truncate(length int, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	text := fmt.Sprint(value)
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	if length <= 3 {
+		return string(runes[:length])
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+// defaultValue 值为空时使用默认值
+// 用法: {{default "unknown" .host}}
+func defaultValue(def interface{}, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return def
+	}
+	return value
+}
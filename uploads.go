@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// 上传文件的保存目录，文件以内容的 SHA-256 命名，请求参数和各队列中只保存文件信息
+const uploadDir = "data/uploads"
+
+// 未被重试队列和定时消息队列引用的上传文件的保留时间，覆盖正在发送和限流暂存中的消息
+const uploadRetention = 24 * time.Hour
+
+// uploadedFile 上传文件的信息，以 JSON 数组形式放入 files 参数
+type uploadedFile struct {
+	Field       string `json:"field,omitempty"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	SHA256      string `json:"sha256"`
+}
+
+// uploadHashPattern 匹配上传文件名，避免 files 参数中的路径访问上传目录以外的文件
+var uploadHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// saveUpload 保存上传的文件并返回内容的 SHA-256，相同内容只保存一份
+func saveUpload(r io.Reader) (string, error) {
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(uploadDir, ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if err := os.Rename(tmp.Name(), filepath.Join(uploadDir, sum)); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// load 读取上传文件的内容
+func (f uploadedFile) load() (multipartFile, error) {
+	if !uploadHashPattern.MatchString(f.SHA256) {
+		return multipartFile{}, fmt.Errorf("上传文件 %s 的 sha256 格式错误", f.Name)
+	}
+	data, err := os.ReadFile(filepath.Join(uploadDir, f.SHA256))
+	if err != nil {
+		return multipartFile{}, fmt.Errorf("读取上传文件 %s 失败: %v", f.Name, err)
+	}
+	return multipartFile{Field: f.Field, Name: f.Name, ContentType: f.ContentType, Data: data}, nil
+}
+
+// parseUploadedFiles 解析 files 参数中的上传文件信息
+func parseUploadedFiles(value string) ([]uploadedFile, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var files []uploadedFile
+	if err := json.Unmarshal([]byte(value), &files); err != nil {
+		return nil, fmt.Errorf("files参数格式错误: %v", err)
+	}
+	return files, nil
+}
+
+// startUploadCleanup 启动后台任务，定期删除不再被队列引用的上传文件
+func startUploadCleanup() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			cleanupUploads()
+			<-ticker.C
+		}
+	}()
+}
+
+// cleanupUploads 删除超过保留时间且未被重试队列和定时消息队列引用的上传文件
+func cleanupUploads() {
+	entries, err := os.ReadDir(uploadDir)
+	if err != nil {
+		return
+	}
+
+	referenced := make(map[string]bool)
+	for _, params := range append(retryQueue.allParams(), scheduleQueue.allParams()...) {
+		files, _ := parseUploadedFiles(params["files"])
+		for _, file := range files {
+			referenced[file.SHA256] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if referenced[entry.Name()] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < uploadRetention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(uploadDir, entry.Name())); err != nil {
+			fmt.Printf("[%s] 删除上传文件失败: %v\n", timestamp(), err)
+		}
+	}
+}
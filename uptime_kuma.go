@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// uptimeKumaPayload Uptime Kuma webhook 请求结构，测试通知中 heartbeat 和 monitor 为空
+type uptimeKumaPayload struct {
+	Heartbeat *uptimeKumaHeartbeat `json:"heartbeat"`
+	Monitor   *uptimeKumaMonitor   `json:"monitor"`
+	Msg       string               `json:"msg"`
+}
+
+// uptimeKumaHeartbeat 心跳检测结果
+type uptimeKumaHeartbeat struct {
+	Status   int             `json:"status"` // 0: 故障, 1: 正常, 2: 等待, 3: 维护
+	Msg      string          `json:"msg"`
+	Time     string          `json:"time"`
+	Timezone string          `json:"timezone"`
+	Ping     json.RawMessage `json:"ping"`
+}
+
+// uptimeKumaMonitor 监控项
+type uptimeKumaMonitor struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Type     string `json:"type"`
+}
+
+// uptimeKumaStatuses 心跳状态对应的名称和图标
+var uptimeKumaStatuses = map[int][2]string{
+	0: {"down", "🔴"},
+	1: {"up", "✅"},
+	2: {"pending", "🟡"},
+	3: {"maintenance", "🔧"},
+}
+
+func init() {
+	RegisterWebhookAdapter("uptime_kuma", parseUptimeKumaWebhook)
+}
+
+// parseUptimeKumaWebhook 将 Uptime Kuma 状态变化通知转换为消息
+func parseUptimeKumaWebhook(req *webhookRequest) ([]webhookMessage, error) {
+	var payload uptimeKumaPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		return nil, err
+	}
+
+	// 测试通知只包含 msg
+	if payload.Heartbeat == nil || payload.Monitor == nil {
+		if payload.Msg == "" {
+			return nil, nil
+		}
+		return []webhookMessage{{Params: map[string]string{
+			"title": "Uptime Kuma",
+			"msg":   payload.Msg,
+		}}}, nil
+	}
+
+	status, ok := uptimeKumaStatuses[payload.Heartbeat.Status]
+	if !ok {
+		status = [2]string{"unknown", "❔"}
+	}
+
+	target := payload.Monitor.URL
+	if target == "" || target == "https://" {
+		target = payload.Monitor.Hostname
+		if target != "" && payload.Monitor.Port > 0 {
+			target = fmt.Sprintf("%s:%d", target, payload.Monitor.Port)
+		}
+	}
+
+	title := fmt.Sprintf("%s [%s] %s", status[1], strings.ToUpper(status[0]), payload.Monitor.Name)
+	lines := []string{title}
+	if target != "" {
+		lines = append(lines, "地址: "+target)
+	}
+	if payload.Heartbeat.Msg != "" {
+		lines = append(lines, "详情: "+payload.Heartbeat.Msg)
+	}
+	if payload.Heartbeat.Time != "" {
+		checkedAt := payload.Heartbeat.Time
+		if payload.Heartbeat.Timezone != "" {
+			checkedAt += " (" + payload.Heartbeat.Timezone + ")"
+		}
+		lines = append(lines, "时间: "+checkedAt)
+	}
+	if ping := strings.TrimSpace(string(payload.Heartbeat.Ping)); ping != "" && ping != "null" {
+		lines = append(lines, "响应时间: "+ping+" ms")
+	}
+
+	return []webhookMessage{{Params: map[string]string{
+		"title":        title,
+		"msg":          truncateBytes(strings.Join(lines, "\n"), req.MaxLength),
+		"status":       status[0],
+		"monitor":      payload.Monitor.Name,
+		"monitor_url":  target,
+		"monitor_type": payload.Monitor.Type,
+	}}}, nil
+}
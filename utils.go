@@ -2,10 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -15,23 +23,115 @@ func timestamp() string {
 	return time.Now().Format("2006-01-02 15:04:05.000")
 }
 
-// httpRequest 通用HTTP请求函数
-func httpRequest(method, url string, data []byte, timeout time.Duration) ([]byte, error) {
-	var req *http.Request
-	var err error
+// newID 生成带时间前缀的随机ID
+func newID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return time.Now().Format("20060102150405") + "-" + hex.EncodeToString(buf)
+}
+
+// saveJSONFile 将数据以JSON格式写入文件，先写临时文件再重命名以保证原子性
+func saveJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
+
+// loadJSONFile 从文件读取JSON数据，文件不存在时不做任何处理
+func loadJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// multipartFile multipart 请求中的文件
+type multipartFile struct {
+	Field       string
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// httpRequest 通用HTTP请求函数，ctx 取消时中止请求
+func httpRequest(ctx context.Context, method, url string, data []byte, timeout time.Duration) ([]byte, error) {
+	if data == nil {
+		return doHTTPRequest(ctx, method, url, nil, "", timeout)
+	}
+	return doHTTPRequest(ctx, method, url, bytes.NewBuffer(data), "application/json;charset=utf-8", timeout)
+}
 
-	if data != nil {
-		req, err = http.NewRequest(method, url, bytes.NewBuffer(data))
-	} else {
-		req, err = http.NewRequest(method, url, nil)
+// httpMultipartRequest 以 multipart/form-data 格式发送 POST 请求，fields 为普通表单字段
+func httpMultipartRequest(ctx context.Context, url string, fields map[string]string, files []multipartFile, timeout time.Duration) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			multipartEscaper.Replace(file.Field), multipartEscaper.Replace(file.Name)))
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write(file.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return doHTTPRequest(ctx, "POST", url, &body, writer.FormDataContentType(), timeout)
+}
+
+// multipartEscaper 转义 Content-Disposition 中的引号和反斜杠
+var multipartEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
 
+// doHTTPRequest 发送请求并返回响应内容，contentType 为空时不设置请求头
+func doHTTPRequest(ctx context.Context, method, url string, body io.Reader, contentType string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	if data != nil {
-		req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	client := &http.Client{Timeout: timeout}
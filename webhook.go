@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// 拆分消息时最多发送的条数，超出部分只显示数量
+const defaultWebhookMaxParts = 5
+
+// webhookMessage 由请求转换得到的一条待发送消息
+type webhookMessage struct {
+	Params map[string]string
+	Data   map[string]interface{} // 模板数据，为空时使用 Params
+}
+
+// webhookRequest webhook 适配器的输入
+type webhookRequest struct {
+	ConfigName string
+	Config     PushConfig
+	Request    *http.Request
+	Body       []byte
+	MaxLength  int // 目标配置的单条消息长度上限（字节），0 表示不限制
+}
+
+// WebhookAdapter 将第三方系统的 webhook 请求转换为一条或多条消息，返回空列表表示无需推送
+type WebhookAdapter func(req *webhookRequest) ([]webhookMessage, error)
+
+// webhook 适配器注册表
+var webhookAdapters = make(map[string]WebhookAdapter)
+
+// RegisterWebhookAdapter 注册 webhook 适配器，名称用于配置的 source 字段和 /配置名/来源 路由
+func RegisterWebhookAdapter(name string, adapter WebhookAdapter) {
+	if _, exists := webhookAdapters[name]; exists {
+		panic(fmt.Sprintf("webhook 适配器重复注册: %s", name))
+	}
+	webhookAdapters[name] = adapter
+}
+
+// GetWebhookAdapter 获取指定名称的 webhook 适配器
+func GetWebhookAdapter(name string) (WebhookAdapter, bool) {
+	adapter, exists := webhookAdapters[name]
+	return adapter, exists
+}
+
+// parseWebhookRequest 使用 webhook 适配器解析请求
+// 查询参数作为每条消息的基础参数，适配器生成的参数优先；JSON 请求体同时以 payload 字段提供给模板
+func parseWebhookRequest(r *http.Request, source, configName string, config PushConfig, excluded ...string) ([]webhookMessage, error) {
+	adapter, exists := GetWebhookAdapter(source)
+	if !exists {
+		return nil, fmt.Errorf("不支持的 webhook 来源: %s", source)
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := adapter(&webhookRequest{
+		ConfigName: configName,
+		Config:     config,
+		Request:    r,
+		Body:       body,
+		MaxLength:  maxMessageLength(configName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 请求失败: %v", source, err)
+	}
+
+	var payload interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&payload) != nil {
+		payload = nil
+	}
+
+	// 忽略查询字符串中的内部参数
+	query := r.URL.Query()
+	for _, key := range excluded {
+		query.Del(key)
+	}
+	for _, key := range reservedParams {
+		query.Del(key)
+	}
+
+	for i, message := range messages {
+		params := make(map[string]string, len(query)+len(message.Params))
+		for key, values := range query {
+			if len(values) > 0 {
+				params[key] = values[0]
+			}
+		}
+		for key, value := range message.Params {
+			params[key] = value
+		}
+
+		data := make(map[string]interface{}, len(params)+1)
+		for key, value := range params {
+			data[key] = value
+		}
+		for key, value := range message.Data {
+			data[key] = value
+		}
+		if payload != nil {
+			data["payload"] = payload
+		}
+
+		messages[i] = webhookMessage{Params: params, Data: data}
+	}
+	return messages, nil
+}
+
+// splitMessage 将标题和若干段落组合成不超过长度上限（字节）的消息
+// 段落不会被拆开（单个段落超长时截断），超过 maxParts 条后剩余段落只显示数量
+func splitMessage(header string, blocks []string, maxLength, maxParts int) []string {
+	if maxParts <= 0 {
+		maxParts = defaultWebhookMaxParts
+	}
+	if maxLength <= 0 {
+		return []string{header + strings.Join(blocks, "\n\n")}
+	}
+
+	// 预留省略说明的空间
+	limit := maxLength - 64
+	if limit <= len(header) {
+		limit = maxLength
+	}
+
+	var parts []string
+	current, count := header, 0
+	for i, block := range blocks {
+		block = truncateBytes(block, limit-len(header))
+		separator := ""
+		if count > 0 {
+			separator = "\n\n"
+		}
+
+		if count > 0 && len(current)+len(separator)+len(block) > limit {
+			if len(parts) == maxParts-1 {
+				return append(parts, current+fmt.Sprintf("\n\n…另有 %d 条未显示", len(blocks)-i))
+			}
+			parts = append(parts, current)
+			current, count, separator = header, 0, ""
+		}
+
+		current += separator + block
+		count++
+	}
+	return append(parts, current)
+}
+
+// truncateBytes 按字节数截断文本，不会截断多字节字符
+func truncateBytes(text string, maxBytes int) string {
+	if maxBytes <= 0 || len(text) <= maxBytes {
+		return text
+	}
+
+	suffix := "…"
+	cut := maxBytes - len(suffix)
+	if cut <= 0 {
+		return ""
+	}
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + suffix
+}
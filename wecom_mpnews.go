@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -47,12 +48,31 @@ type mpNewsRequest struct {
 	MPNews  mpNews `json:"mpnews"`
 }
 
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("wecom_mpnews",
+		[]ConfigField{
+			{Name: "APIBaseURL", Required: true, Description: "企业微信接口地址"},
+			{Name: "CorpID", Required: true, Description: "企业ID"},
+			{Name: "CorpSecret", Required: true, Description: "应用密钥"},
+			{Name: "AgentID", Required: true, Description: "应用ID"},
+			{Name: "ThumbMediaID", Description: "图文消息缩略图的媒体ID"},
+			{Name: "Author", Description: "作者名称"},
+			{Name: "DefaultTitle", Description: "默认标题"},
+		},
+		func(configData map[string]interface{}) error {
+			_, err := convertToWecomMPNewsConfig(configData)
+			return err
+		},
+		SendWecomMPNews,
+	), 666*1024))
+}
+
 // getWecomAccessToken 获取企业微信访问令牌
-func getWecomAccessToken(config WecomMPNewsConfig) (string, error) {
+func getWecomAccessToken(ctx context.Context, config WecomMPNewsConfig) (string, error) {
 	url := fmt.Sprintf("%s/cgi-bin/gettoken?corpid=%s&corpsecret=%s",
 		config.APIBaseURL, config.CorpID, config.CorpSecret)
 
-	response, err := httpRequest("GET", url, nil, 30*time.Second)
+	response, err := httpRequest(ctx, "GET", url, nil, 30*time.Second)
 	if err != nil {
 		return "", err
 	}
@@ -96,7 +116,7 @@ func createWecomMPNewsData(config WecomMPNewsConfig, title, message string) mpNe
 }
 
 // SendWecomMPNews 发送企业微信图文消息 - 统一接口
-func SendWecomMPNews(configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+func SendWecomMPNews(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
 	// 转换配置
 	config, err := convertToWecomMPNewsConfig(configData)
 	if err != nil {
@@ -117,7 +137,7 @@ func SendWecomMPNews(configName string, configData map[string]interface{}, param
 	message := params["msg"]
 
 	// 获取访问令牌
-	accessToken, err := getWecomAccessToken(config)
+	accessToken, err := getWecomAccessToken(ctx, config)
 	if err != nil {
 		return "", err
 	}
@@ -133,7 +153,7 @@ func SendWecomMPNews(configName string, configData map[string]interface{}, param
 		return "", err
 	}
 
-	response, err := httpRequest("POST", url, jsonData, 30*time.Second)
+	response, err := httpRequest(ctx, "POST", url, jsonData, 30*time.Second)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -11,6 +12,7 @@ import (
 type WecomRobotTextConfig struct {
 	APIBaseURL string   `json:"APIBaseURL"`
 	Keys       []string `json:"Keys"`
+	Mention    mentionConfig
 }
 
 // wecomRobotTextRequest 企业微信群机器人文本请求结构
@@ -21,7 +23,23 @@ type wecomRobotTextRequest struct {
 
 // wecomRobotTextMessageBody 文本消息结构
 type wecomRobotTextMessageBody struct {
-	Content string `json:"content"`
+	Content             string   `json:"content"`
+	MentionedList       []string `json:"mentioned_list,omitempty"`
+	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"`
+}
+
+func init() {
+	RegisterChannel(WithMaxMessageLength(NewChannel("wecom_robot_text",
+		append([]ConfigField{
+			{Name: "APIBaseURL", Required: true, Description: "企业微信接口地址"},
+			{Name: "Keys", Required: true, Description: "机器人Key列表，随机选择一个发送"},
+		}, mentionSchema()...),
+		func(configData map[string]interface{}) error {
+			_, err := convertToWecomRobotTextConfig(configData)
+			return err
+		},
+		SendWecomRobotText,
+	), 2048))
 }
 
 // convertToWecomRobotTextConfig 转换配置数据到企业微信群机器人文本配置
@@ -51,11 +69,17 @@ func convertToWecomRobotTextConfig(configData map[string]interface{}) (*WecomRob
 		return nil, fmt.Errorf("配置 Keys 不能为空")
 	}
 
+	mention, err := convertToMentionConfig(configData)
+	if err != nil {
+		return nil, err
+	}
+	config.Mention = mention
+
 	return config, nil
 }
 
 // SendWecomRobotText 发送企业微信群机器人文本消息
-func SendWecomRobotText(configName string, configData map[string]interface{}, params map[string]string) (string, error) {
+func SendWecomRobotText(ctx context.Context, configName string, configData map[string]interface{}, params map[string]string) (string, error) {
 	config, err := convertToWecomRobotTextConfig(configData)
 	if err != nil {
 		return "", err
@@ -70,11 +94,20 @@ func SendWecomRobotText(configName string, configData map[string]interface{}, pa
 	// 构造完整 Webhook URL
 	url := fmt.Sprintf("%s/cgi-bin/webhook/send?key=%s", config.APIBaseURL, selectedKey)
 
+	// 提醒对象由企业微信追加到消息末尾，@all 表示提醒所有人
+	mention := config.Mention.withParams(params)
+	mentionedList := mention.AtUserIds
+	if mention.AtAll {
+		mentionedList = append(mentionedList, "@all")
+	}
+
 	// 构造请求数据
 	requestData := wecomRobotTextRequest{
 		MsgType: "text",
 		Text: wecomRobotTextMessageBody{
-			Content: message,
+			Content:             message,
+			MentionedList:       mentionedList,
+			MentionedMobileList: mention.AtMobiles,
 		},
 	}
 
@@ -84,7 +117,7 @@ func SendWecomRobotText(configName string, configData map[string]interface{}, pa
 	}
 
 	// 发送请求
-	response, err := httpRequest("POST", url, jsonData, 30*time.Second)
+	response, err := httpRequest(ctx, "POST", url, jsonData, 30*time.Second)
 	if err != nil {
 		return "", err
 	}